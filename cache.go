@@ -0,0 +1,284 @@
+package ectolinq
+
+import "sync"
+
+// Cache is a thread-safe key-value store bounded by a capacity.
+// Every entry has a cost, one by default. When a write takes the total cost over the capacity,
+// the eviction policy chooses entries to evict until the cache fits again
+type Cache[K comparable, V any] struct {
+	mutex     sync.Mutex
+	entries   map[K]cacheEntry[V]
+	policy    EvictionPolicy[K]
+	capacity  int64
+	size      int64
+	cost      func(K, V) int64
+	onEvict   func(K, V, EvictionReason)
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type cacheEntry[V any] struct {
+	value V
+	cost  int64
+}
+
+// CacheOptions configures a Cache created with NewCacheWithOptions
+type CacheOptions[K comparable, V any] struct {
+	// Capacity is the maximum total cost of the entries in the cache. A capacity of zero or less stores nothing
+	Capacity int64
+	// Cost returns the cost of an entry and must not be negative. Nil gives every entry a cost of one, so Capacity is a number of entries
+	Cost func(key K, value V) int64
+	// Policy chooses the entries to evict. Nil uses a new LRUPolicy. A policy must not be shared between caches
+	Policy EvictionPolicy[K]
+	// OnEvict is called when an entry is evicted for capacity, removed or has its value replaced. It runs after the cache's lock is released,
+	// on the goroutine that caused the eviction, so it may use the cache
+	OnEvict func(key K, value V, reason EvictionReason)
+}
+
+// CacheStats counts how a Cache has been used
+type CacheStats struct {
+	// Hits is the number of Get calls that found their key
+	Hits int64
+	// Misses is the number of Get calls that did not find their key
+	Misses int64
+	// Evictions is the number of entries evicted to stay within the capacity
+	Evictions int64
+}
+
+// HitRatio returns the fraction of Get calls that found their key, or 0 if there have been none
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewCache creates a new least recently used cache that holds up to capacity entries
+// capacity: The maximum number of entries
+func NewCache[K comparable, V any](capacity int) *Cache[K, V] {
+	return NewCacheWithOptions(CacheOptions[K, V]{Capacity: int64(capacity)})
+}
+
+// NewCacheWithOptions creates a new cache with the given options
+// options: The capacity, cost function, eviction policy and callback to use
+func NewCacheWithOptions[K comparable, V any](options CacheOptions[K, V]) *Cache[K, V] {
+	policy := options.Policy
+	if policy == nil {
+		policy = NewLRUPolicy[K]()
+	}
+	cost := options.Cost
+	if cost == nil {
+		cost = func(K, V) int64 { return 1 }
+	}
+	return &Cache[K, V]{
+		entries:  make(map[K]cacheEntry[V]),
+		policy:   policy,
+		capacity: options.Capacity,
+		cost:     cost,
+		onEvict:  options.OnEvict,
+	}
+}
+
+// Get returns the value in the cache for the given key and records the use with the eviction policy
+// key: The key to get the value for
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return entry.value, false
+	}
+	c.hits++
+	c.policy.Access(key)
+	return entry.value, true
+}
+
+// Peek returns the value in the cache for the given key without recording a use or counting a hit or miss
+// key: The key to get the value for
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	return entry.value, ok
+}
+
+// Set sets the value in the cache for the given key, evicting other entries if the cache goes over its capacity.
+// An entry whose cost alone is more than the capacity is not stored and is reported to OnEvict straight away
+// key: The key to set the value for
+// value: The value to set
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.write(func(evicted *[]eviction[K, V]) {
+		c.set(key, value, evicted)
+	})
+}
+
+// ContainsKey returns if the cache contains the given key, without recording a use
+// key: The key to check for
+func (c *Cache[K, V]) ContainsKey(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.entries[key]
+	return ok
+}
+
+// Remove removes the value in the cache for the given key. It returns false if the key was missing
+// key: The key to remove the value for
+func (c *Cache[K, V]) Remove(key K) bool {
+	removed := false
+	c.write(func(evicted *[]eviction[K, V]) {
+		if entry, ok := c.entries[key]; ok {
+			c.policy.Remove(key)
+			c.delete(key, entry, EvictionRemoved, evicted)
+			removed = true
+		}
+	})
+	return removed
+}
+
+// Clear removes all values from the cache. The statistics are kept
+func (c *Cache[K, V]) Clear() {
+	c.write(func(evicted *[]eviction[K, V]) {
+		for key, entry := range c.entries {
+			c.policy.Remove(key)
+			c.delete(key, entry, EvictionRemoved, evicted)
+		}
+	})
+}
+
+// Resize changes the capacity of the cache, evicting entries if it is now over capacity
+// capacity: The new maximum total cost
+func (c *Cache[K, V]) Resize(capacity int64) {
+	c.write(func(evicted *[]eviction[K, V]) {
+		c.capacity = capacity
+		c.evict(0, evicted)
+	})
+}
+
+// Count returns the number of values in the cache
+func (c *Cache[K, V]) Count() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the total cost of the values in the cache
+func (c *Cache[K, V]) Size() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.size
+}
+
+// Capacity returns the maximum total cost of the values in the cache
+func (c *Cache[K, V]) Capacity() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.capacity
+}
+
+// Keys returns the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keys := make([]K, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ToMap returns a copy of the cache as a map
+func (c *Cache[K, V]) ToMap() map[K]V {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	m := make(map[K]V, len(c.entries))
+	for key, entry := range c.entries {
+		m[key] = entry.value
+	}
+	return m
+}
+
+// Stats returns the hit, miss and eviction counts of the cache
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// write runs fn under the lock, then reports the entries fn evicted to OnEvict once the lock is released
+func (c *Cache[K, V]) write(fn func(evicted *[]eviction[K, V])) {
+	var evicted []eviction[K, V]
+	func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		fn(&evicted)
+	}()
+	for _, e := range evicted {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+}
+
+// set stores the entry, first evicting other entries to make room for a new key. The caller must hold the lock
+func (c *Cache[K, V]) set(key K, value V, evicted *[]eviction[K, V]) {
+	cost := max(c.cost(key, value), 0)
+	previous, exists := c.entries[key]
+	if exists {
+		c.size -= previous.cost
+		c.record(evicted, key, previous.value, EvictionReplaced)
+	}
+
+	if cost > c.capacity {
+		if exists {
+			delete(c.entries, key)
+			c.policy.Remove(key)
+		}
+		c.evictions++
+		c.record(evicted, key, value, EvictionCapacity)
+		return
+	}
+
+	if exists {
+		c.entries[key] = cacheEntry[V]{value: value, cost: cost}
+		c.size += cost
+		c.policy.Access(key)
+		c.evict(0, evicted)
+		return
+	}
+	c.evict(cost, evicted)
+	c.entries[key] = cacheEntry[V]{value: value, cost: cost}
+	c.size += cost
+	c.policy.Add(key)
+}
+
+// evict asks the policy for entries to evict until the cache has room for extra more cost. The caller must hold the lock
+func (c *Cache[K, V]) evict(extra int64, evicted *[]eviction[K, V]) {
+	for c.size+extra > c.capacity {
+		key, ok := c.policy.Evict()
+		if !ok {
+			return
+		}
+		if entry, ok := c.entries[key]; ok {
+			c.evictions++
+			c.delete(key, entry, EvictionCapacity, evicted)
+		}
+	}
+}
+
+// delete removes the entry, which the policy has already forgotten. The caller must hold the lock
+func (c *Cache[K, V]) delete(key K, entry cacheEntry[V], reason EvictionReason, evicted *[]eviction[K, V]) {
+	delete(c.entries, key)
+	c.size -= entry.cost
+	c.record(evicted, key, entry.value, reason)
+}
+
+// record adds an evicted entry to be reported to OnEvict. It does nothing when there is no OnEvict callback
+func (c *Cache[K, V]) record(evicted *[]eviction[K, V], key K, value V, reason EvictionReason) {
+	if c.onEvict != nil {
+		*evicted = append(*evicted, eviction[K, V]{key: key, value: value, reason: reason})
+	}
+}
@@ -0,0 +1,319 @@
+package ectolinq
+
+// EvictionPolicy decides which entry a Cache evicts when it is over capacity.
+// A Cache calls its policy with the cache's lock held, so implementations do not need to be thread-safe,
+// but each Cache needs its own policy instance
+type EvictionPolicy[K comparable] interface {
+	// Add records a key that was added to the cache
+	Add(key K)
+	// Access records a read or update of a key already in the cache
+	Access(key K)
+	// Remove forgets a key that was removed from the cache by the caller
+	Remove(key K)
+	// Evict chooses the next key to evict, forgets it and returns it. It returns false if the policy holds no keys
+	Evict() (K, bool)
+}
+
+// LRUPolicy evicts the least recently used key
+type LRUPolicy[K comparable] struct {
+	order linkedList[K]
+	nodes map[K]*listNode[K]
+}
+
+// NewLRUPolicy creates a new least recently used eviction policy
+func NewLRUPolicy[K comparable]() *LRUPolicy[K] {
+	return &LRUPolicy[K]{nodes: make(map[K]*listNode[K])}
+}
+
+// Add records a key that was added to the cache
+// key: The key that was added
+func (p *LRUPolicy[K]) Add(key K) {
+	if node, ok := p.nodes[key]; ok {
+		p.order.moveToFront(node)
+		return
+	}
+	p.nodes[key] = p.order.pushFront(key)
+}
+
+// Access marks a key as the most recently used
+// key: The key that was used
+func (p *LRUPolicy[K]) Access(key K) {
+	if node, ok := p.nodes[key]; ok {
+		p.order.moveToFront(node)
+	}
+}
+
+// Remove forgets a key
+// key: The key that was removed
+func (p *LRUPolicy[K]) Remove(key K) {
+	if node, ok := p.nodes[key]; ok {
+		p.order.remove(node)
+		delete(p.nodes, key)
+	}
+}
+
+// Evict forgets and returns the least recently used key
+func (p *LRUPolicy[K]) Evict() (K, bool) {
+	node := p.order.back()
+	if node == nil {
+		var zero K
+		return zero, false
+	}
+	p.Remove(node.value)
+	return node.value, true
+}
+
+// LFUPolicy evicts the least frequently used key, breaking ties by evicting the least recently used of them
+type LFUPolicy[K comparable] struct {
+	nodes   map[K]*lfuNode[K]
+	buckets map[int]*linkedList[K]
+	minimum int
+}
+
+type lfuNode[K comparable] struct {
+	node      *listNode[K]
+	frequency int
+}
+
+// NewLFUPolicy creates a new least frequently used eviction policy
+func NewLFUPolicy[K comparable]() *LFUPolicy[K] {
+	return &LFUPolicy[K]{
+		nodes:   make(map[K]*lfuNode[K]),
+		buckets: make(map[int]*linkedList[K]),
+	}
+}
+
+// Add records a key with a use count of one
+// key: The key that was added
+func (p *LFUPolicy[K]) Add(key K) {
+	if _, ok := p.nodes[key]; ok {
+		p.Access(key)
+		return
+	}
+	p.nodes[key] = &lfuNode[K]{node: p.bucket(1).pushFront(key), frequency: 1}
+	p.minimum = 1
+}
+
+// Access increments the use count of a key
+// key: The key that was used
+func (p *LFUPolicy[K]) Access(key K) {
+	entry, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+	p.unlink(entry)
+	if p.minimum == entry.frequency && p.buckets[entry.frequency] == nil {
+		p.minimum++
+	}
+	entry.frequency++
+	entry.node = p.bucket(entry.frequency).pushFront(key)
+}
+
+// Remove forgets a key
+// key: The key that was removed
+func (p *LFUPolicy[K]) Remove(key K) {
+	if entry, ok := p.nodes[key]; ok {
+		p.unlink(entry)
+		delete(p.nodes, key)
+	}
+}
+
+// Evict forgets and returns the least frequently used key
+func (p *LFUPolicy[K]) Evict() (K, bool) {
+	if len(p.nodes) == 0 {
+		var zero K
+		return zero, false
+	}
+	if p.buckets[p.minimum] == nil {
+		// The least frequent bucket was emptied by Remove, so find the next one
+		p.minimum = 0
+		for frequency := range p.buckets {
+			if p.minimum == 0 || frequency < p.minimum {
+				p.minimum = frequency
+			}
+		}
+	}
+	key := p.buckets[p.minimum].back().value
+	p.Remove(key)
+	return key, true
+}
+
+func (p *LFUPolicy[K]) bucket(frequency int) *linkedList[K] {
+	bucket, ok := p.buckets[frequency]
+	if !ok {
+		bucket = &linkedList[K]{}
+		p.buckets[frequency] = bucket
+	}
+	return bucket
+}
+
+// unlink removes the entry from its frequency bucket, dropping the bucket once it is empty
+func (p *LFUPolicy[K]) unlink(entry *lfuNode[K]) {
+	bucket := p.buckets[entry.frequency]
+	bucket.remove(entry.node)
+	if bucket.len == 0 {
+		delete(p.buckets, entry.frequency)
+	}
+}
+
+// ARCPolicy is an adaptive replacement cache policy. It keeps keys used once and keys used more than once in separate LRU lists,
+// and remembers recently evicted keys to learn which list deserves more of the cache. This makes it resistant to large scans
+// that would flush an LRU cache. The adaptation is measured in entries, whatever cost function the cache uses
+type ARCPolicy[K comparable] struct {
+	recent        linkedList[K] // T1: keys seen once recently
+	frequent      linkedList[K] // T2: keys seen at least twice recently
+	recentGhost   linkedList[K] // B1: keys recently evicted from recent
+	frequentGhost linkedList[K] // B2: keys recently evicted from frequent
+	nodes         map[K]*arcNode[K]
+	target        int // the number of entries recent should aim for
+}
+
+type arcNode[K comparable] struct {
+	node *listNode[K]
+	list *linkedList[K]
+}
+
+// NewARCPolicy creates a new adaptive replacement cache policy
+func NewARCPolicy[K comparable]() *ARCPolicy[K] {
+	return &ARCPolicy[K]{nodes: make(map[K]*arcNode[K])}
+}
+
+// Add records a key that was added to the cache. Keys that were evicted recently go straight to the frequent list and adapt the target
+// key: The key that was added
+func (p *ARCPolicy[K]) Add(key K) {
+	entry, ok := p.nodes[key]
+	switch {
+	case !ok:
+		p.push(key, &p.recent)
+		return
+	case entry.list == &p.recentGhost:
+		p.target = min(p.target+max(p.frequentGhost.len/entry.list.len, 1), p.live())
+	case entry.list == &p.frequentGhost:
+		p.target = max(p.target-max(p.recentGhost.len/entry.list.len, 1), 0)
+	}
+	p.forget(key)
+	p.push(key, &p.frequent)
+}
+
+// Access moves a key to the front of the frequent list
+// key: The key that was used
+func (p *ARCPolicy[K]) Access(key K) {
+	if entry, ok := p.nodes[key]; ok && (entry.list == &p.recent || entry.list == &p.frequent) {
+		p.forget(key)
+		p.push(key, &p.frequent)
+	}
+}
+
+// Remove forgets a key, including any record of it being evicted
+// key: The key that was removed
+func (p *ARCPolicy[K]) Remove(key K) {
+	p.forget(key)
+}
+
+// Evict forgets and returns the least recently used key of the recent list while it is over its target, or of the frequent list otherwise.
+// The evicted key is remembered so that adding it again can adapt the target
+func (p *ARCPolicy[K]) Evict() (K, bool) {
+	var from, ghost *linkedList[K]
+	switch {
+	case p.recent.len > 0 && (p.recent.len > p.target || p.frequent.len == 0):
+		from, ghost = &p.recent, &p.recentGhost
+	case p.frequent.len > 0:
+		from, ghost = &p.frequent, &p.frequentGhost
+	default:
+		var zero K
+		return zero, false
+	}
+
+	key := from.back().value
+	p.forget(key)
+	p.push(key, ghost)
+	p.trimGhosts()
+	return key, true
+}
+
+// live returns the number of keys in the cache
+func (p *ARCPolicy[K]) live() int {
+	return p.recent.len + p.frequent.len
+}
+
+// trimGhosts bounds the evicted key history after an eviction. The cache held one more key before the eviction,
+// so that is taken as its capacity c: recent and its ghosts hold at most c keys, and all four lists at most 2c
+func (p *ARCPolicy[K]) trimGhosts() {
+	capacity := p.live() + 1
+	for p.recent.len+p.recentGhost.len > capacity && p.recentGhost.len > 0 {
+		p.forget(p.recentGhost.back().value)
+	}
+	for p.live()+p.recentGhost.len+p.frequentGhost.len > 2*capacity && p.frequentGhost.len > 0 {
+		p.forget(p.frequentGhost.back().value)
+	}
+}
+
+func (p *ARCPolicy[K]) push(key K, list *linkedList[K]) {
+	p.nodes[key] = &arcNode[K]{node: list.pushFront(key), list: list}
+}
+
+func (p *ARCPolicy[K]) forget(key K) {
+	if entry, ok := p.nodes[key]; ok {
+		entry.list.remove(entry.node)
+		delete(p.nodes, key)
+	}
+}
+
+// linkedList is a minimal doubly linked list used by the eviction policies. The zero value is an empty list
+type linkedList[T any] struct {
+	head *listNode[T]
+	tail *listNode[T]
+	len  int
+}
+
+type listNode[T any] struct {
+	value T
+	prev  *listNode[T]
+	next  *listNode[T]
+}
+
+func (l *linkedList[T]) pushFront(value T) *listNode[T] {
+	node := &listNode[T]{value: value}
+	l.link(node)
+	return node
+}
+
+func (l *linkedList[T]) link(node *listNode[T]) {
+	node.prev = nil
+	node.next = l.head
+	if l.head != nil {
+		l.head.prev = node
+	} else {
+		l.tail = node
+	}
+	l.head = node
+	l.len++
+}
+
+func (l *linkedList[T]) remove(node *listNode[T]) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		l.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		l.tail = node.prev
+	}
+	node.prev = nil
+	node.next = nil
+	l.len--
+}
+
+func (l *linkedList[T]) moveToFront(node *listNode[T]) {
+	if l.head == node {
+		return
+	}
+	l.remove(node)
+	l.link(node)
+}
+
+func (l *linkedList[T]) back() *listNode[T] {
+	return l.tail
+}
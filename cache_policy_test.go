@@ -0,0 +1,115 @@
+package ectolinq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// evictAll drains a policy, returning the keys in eviction order
+func evictAll[K comparable](policy EvictionPolicy[K]) []K {
+	var keys []K
+	for key, ok := policy.Evict(); ok; key, ok = policy.Evict() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestLRUPolicy(t *testing.T) {
+	t.Run("Evicts the least recently used key", func(t *testing.T) {
+		p := NewLRUPolicy[string]()
+		p.Add("a")
+		p.Add("b")
+		p.Add("c")
+		p.Access("a")
+		assert.Equal(t, []string{"b", "c", "a"}, evictAll[string](p))
+	})
+
+	t.Run("Remove and empty policy", func(t *testing.T) {
+		p := NewLRUPolicy[string]()
+		_, ok := p.Evict()
+		assert.False(t, ok, "Evict should return false for an empty policy")
+		p.Add("a")
+		p.Add("b")
+		p.Remove("a")
+		p.Remove("missing")
+		p.Access("missing")
+		assert.Equal(t, []string{"b"}, evictAll[string](p))
+	})
+}
+
+func TestLFUPolicy(t *testing.T) {
+	t.Run("Evicts the least frequently used key", func(t *testing.T) {
+		p := NewLFUPolicy[string]()
+		p.Add("a")
+		p.Add("b")
+		p.Add("c")
+		p.Access("a")
+		p.Access("a")
+		p.Access("b")
+		assert.Equal(t, []string{"c", "b", "a"}, evictAll[string](p))
+	})
+
+	t.Run("Ties evict the least recently used key", func(t *testing.T) {
+		p := NewLFUPolicy[string]()
+		p.Add("x")
+		p.Add("y")
+		p.Add("z")
+		p.Access("y")
+		p.Access("x")
+		assert.Equal(t, []string{"z", "y", "x"}, evictAll[string](p))
+	})
+
+	t.Run("Remove the least frequent key", func(t *testing.T) {
+		p := NewLFUPolicy[string]()
+		p.Add("a")
+		p.Add("b")
+		p.Access("b")
+		p.Access("b")
+		p.Remove("a")
+		p.Add("c")
+		p.Access("c")
+		p.Remove("c")
+		key, ok := p.Evict()
+		assert.True(t, ok)
+		assert.Equal(t, "b", key)
+		_, ok = p.Evict()
+		assert.False(t, ok)
+	})
+}
+
+func TestARCPolicy(t *testing.T) {
+	t.Run("Evicts keys seen once before keys seen twice", func(t *testing.T) {
+		p := NewARCPolicy[string]()
+		p.Add("hot")
+		p.Access("hot")
+		p.Add("a")
+		p.Add("b")
+		assert.Equal(t, []string{"a", "b", "hot"}, evictAll[string](p))
+	})
+
+	t.Run("Re-adding an evicted key adapts the target", func(t *testing.T) {
+		p := NewARCPolicy[string]()
+		p.Add("a")
+		p.Add("b")
+		p.Add("c")
+		key, _ := p.Evict()
+		assert.Equal(t, "a", key)
+		assert.Equal(t, 0, p.target)
+
+		p.Add("a")
+		assert.Equal(t, 1, p.target, "A hit in the recent ghost list should grow the recent target")
+		assert.Equal(t, []string{"b", "a", "c"}, evictAll[string](p), "Recent should only give up entries while it is over the target")
+	})
+
+	t.Run("Remove forgets evicted keys", func(t *testing.T) {
+		p := NewARCPolicy[string]()
+		p.Add("a")
+		p.Add("b")
+		p.Evict()
+		p.Remove("a")
+		p.Add("a")
+		assert.Equal(t, 0, p.target, "A removed key should be treated as new")
+		assert.Equal(t, []string{"b", "a"}, evictAll[string](p))
+	})
+}
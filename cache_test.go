@@ -0,0 +1,212 @@
+package ectolinq
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCache(t *testing.T) {
+	t.Run("Create new cache", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		require.NotNil(t, c)
+		assert.Equal(t, 0, c.Count())
+		assert.Equal(t, int64(2), c.Capacity())
+	})
+
+	t.Run("Zero capacity stores nothing", func(t *testing.T) {
+		c := NewCache[string, int](0)
+		c.Set("a", 1)
+		assert.Equal(t, 0, c.Count())
+	})
+}
+
+func TestCacheGetSet(t *testing.T) {
+	c := NewCache[string, int](3)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("a", 10)
+	value, _ = c.Peek("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 2, c.Count())
+	assert.True(t, c.ContainsKey("b"))
+	assert.ElementsMatch(t, []string{"a", "b"}, c.Keys())
+	assert.Equal(t, map[string]int{"a": 10, "b": 2}, c.ToMap())
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Run("LRU by default", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Get("a")
+		c.Set("c", 3)
+		assert.ElementsMatch(t, []string{"a", "c"}, c.Keys(), "The least recently used key should be evicted")
+	})
+
+	t.Run("Peek does not count as a use", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Peek("a")
+		c.Set("c", 3)
+		assert.ElementsMatch(t, []string{"b", "c"}, c.Keys())
+	})
+
+	t.Run("LFU policy", func(t *testing.T) {
+		c := NewCacheWithOptions(CacheOptions[string, int]{Capacity: 2, Policy: NewLFUPolicy[string]()})
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Get("a")
+		c.Get("a")
+		c.Get("b")
+		c.Set("c", 3)
+		c.Set("d", 4)
+		assert.ElementsMatch(t, []string{"a", "d"}, c.Keys())
+	})
+
+	t.Run("ARC resists scans", func(t *testing.T) {
+		newCache := func(policy EvictionPolicy[string]) *Cache[string, int] {
+			c := NewCacheWithOptions(CacheOptions[string, int]{Capacity: 4, Policy: policy})
+			c.Set("hot1", 1)
+			c.Set("hot2", 2)
+			c.Get("hot1")
+			c.Get("hot2")
+			for i := 0; i < 100; i++ {
+				c.Set(fmt.Sprint("scan", i), i)
+			}
+			return c
+		}
+
+		arc := newCache(NewARCPolicy[string]())
+		assert.True(t, arc.ContainsKey("hot1"), "ARC should keep keys used more than once")
+		assert.True(t, arc.ContainsKey("hot2"))
+
+		lru := newCache(NewLRUPolicy[string]())
+		assert.False(t, lru.ContainsKey("hot1"), "LRU is flushed by the scan")
+	})
+
+	t.Run("Cost function", func(t *testing.T) {
+		c := NewCacheWithOptions(CacheOptions[string, string]{
+			Capacity: 10,
+			Cost:     func(_ string, value string) int64 { return int64(len(value)) },
+		})
+		c.Set("a", "12345")
+		c.Set("b", "1234")
+		assert.Equal(t, int64(9), c.Size())
+		c.Set("c", "12")
+		assert.ElementsMatch(t, []string{"b", "c"}, c.Keys(), "Entries should be evicted until the total cost fits")
+		assert.Equal(t, int64(6), c.Size())
+
+		c.Set("b", "1")
+		assert.Equal(t, int64(3), c.Size(), "Replacing a value should update the total cost")
+	})
+
+	t.Run("Entries larger than the capacity are not stored", func(t *testing.T) {
+		log := &evictionLog[string, string]{}
+		c := NewCacheWithOptions(CacheOptions[string, string]{
+			Capacity: 3,
+			Cost:     func(_ string, value string) int64 { return int64(len(value)) },
+			OnEvict:  log.OnEvict,
+		})
+		c.Set("a", "1")
+		c.Set("b", "12345")
+		assert.Equal(t, []string{"a"}, c.Keys(), "An oversized entry should not flush the cache")
+		c.Set("a", "12345")
+		assert.Equal(t, 0, c.Count())
+		assert.Equal(t, []string{"b=12345 capacity", "a=1 replaced", "a=12345 capacity"}, log.Entries())
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		c := NewCache[int, int](5)
+		for i := 0; i < 5; i++ {
+			c.Set(i, i)
+		}
+		c.Resize(2)
+		assert.ElementsMatch(t, []int{3, 4}, c.Keys())
+		assert.Equal(t, int64(2), c.Capacity())
+	})
+}
+
+func TestCacheRemove(t *testing.T) {
+	log := &evictionLog[string, int]{}
+	c := NewCacheWithOptions(CacheOptions[string, int]{Capacity: 2, OnEvict: log.OnEvict})
+	c.Set("a", 1)
+	c.Set("a", 2)
+	assert.True(t, c.Remove("a"))
+	assert.False(t, c.Remove("a"))
+	c.Set("b", 3)
+	c.Set("c", 4)
+	c.Set("d", 5)
+	c.Clear()
+	assert.Equal(t, 0, c.Count())
+	assert.Equal(t, int64(0), c.Size())
+
+	entries := log.Entries()
+	assert.Equal(t, []string{"a=1 replaced", "a=2 removed", "b=3 capacity"}, entries[:3])
+	assert.ElementsMatch(t, []string{"c=4 removed", "d=5 removed"}, entries[3:])
+
+	c.Set("e", 6)
+	c.Set("f", 7)
+	c.Set("g", 8)
+	assert.ElementsMatch(t, []string{"f", "g"}, c.Keys(), "The policy should be reset by Clear")
+}
+
+func TestCacheStats(t *testing.T) {
+	c := NewCache[string, int](1)
+	assert.Equal(t, float64(0), c.Stats().HitRatio())
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("b", 2)
+
+	stats := c.Stats()
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 1, Evictions: 1}, stats)
+	assert.Equal(t, 0.75, stats.HitRatio())
+}
+
+func TestCacheConcurrentAccess(t *testing.T) {
+	policies := map[string]func() EvictionPolicy[int]{
+		"LRU": func() EvictionPolicy[int] { return NewLRUPolicy[int]() },
+		"LFU": func() EvictionPolicy[int] { return NewLFUPolicy[int]() },
+		"ARC": func() EvictionPolicy[int] { return NewARCPolicy[int]() },
+	}
+	for name, newPolicy := range policies {
+		t.Run(name, func(t *testing.T) {
+			c := NewCacheWithOptions(CacheOptions[int, int]{Capacity: 64, Policy: newPolicy()})
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := (g*31 + i) % 200
+						if _, ok := c.Get(key); !ok {
+							c.Set(key, key)
+						}
+						if i%50 == 0 {
+							c.Remove(key)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			assert.LessOrEqual(t, c.Count(), 64)
+			assert.Equal(t, int64(c.Count()), c.Size())
+			for key, value := range c.ToMap() {
+				assert.Equal(t, key, value)
+			}
+		})
+	}
+}
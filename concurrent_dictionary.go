@@ -1,154 +1,760 @@
 package ectolinq
 
-import "sync"
+import (
+	"hash/maphash"
+	"iter"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
-// ConcurrentDictionary is a thread-safe dictionary
-// utilizes a read-write mutex to allow multiple readers or a single writer
-type ConcurrentDictionary[T any] struct {
-	values *Dictionary[T]
-	mutex  sync.RWMutex
+// ConcurrentDictionary is a thread-safe dictionary.
+// The keys are split across one or more shards, each guarded by its own read-write mutex that allows multiple readers or a single writer.
+// A dictionary created with NewConcurrentDictionary has a single shard; use NewConcurrentDictionaryWithOptions to spread writers across several.
+// Operations that visit every entry (Keys, ToArray, ToMap, All...) are weakly consistent: they read each shard in small batches under its read lock,
+// so writers never wait for a whole shard, but writes made while they run may or may not be seen. Use Snapshot for a point-in-time view.
+// Entries can expire after a time to live; expired entries are treated as missing and removed lazily, or by a background janitor
+type ConcurrentDictionary[K comparable, V any] struct {
+	shards     []*concurrentShard[K, V]
+	hash       func(K) uint64
+	equals     func(V, V) bool
+	defaultTTL time.Duration
+	clock      func() time.Time
+	onEvict    func(K, V, EvictionReason)
+	// expiring is set once any entry has been given a TTL, so dictionaries that never expire skip reading the clock
+	expiring atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// ConcurrentDictionaryOptions configures a ConcurrentDictionary created with NewConcurrentDictionaryWithOptions
+type ConcurrentDictionaryOptions[K comparable, V any] struct {
+	// ShardCount is the number of independently locked shards. Zero or less uses DefaultShardCount
+	ShardCount int
+	// Hash maps a key to its shard. It must return the same value for equal keys. Nil uses hash/maphash
+	Hash func(K) uint64
+	// Comparer reports whether two values are equal. It is used by TryUpdate, ContainsValue and RemoveValue. Nil uses Equals
+	Comparer func(a V, b V) bool
+	// DefaultTTL is how long entries live when they are written by any method other than SetWithTTL. Zero means they never expire
+	DefaultTTL time.Duration
+	// JanitorInterval is how often a background goroutine deletes expired entries. Zero disables it and expired entries are removed lazily.
+	// Call Close to stop the janitor once the dictionary is no longer needed
+	JanitorInterval time.Duration
+	// Clock returns the current time used for expiry. Nil uses time.Now
+	Clock func() time.Time
+	// OnEvict is called when an entry expires, is removed or has its value replaced. It runs after the dictionary's lock is released,
+	// on the goroutine that caused the eviction, so it may use the dictionary
+	OnEvict func(key K, value V, reason EvictionReason)
+}
+
+// EvictionReason describes why an entry left a dictionary or cache
+type EvictionReason int
+
+const (
+	// EvictionExpired means the entry's time to live ran out
+	EvictionExpired EvictionReason = iota + 1
+	// EvictionRemoved means the entry was removed by Remove, RemoveWhere, Clear, GetAndDelete or Compute
+	EvictionRemoved
+	// EvictionReplaced means the entry's value was overwritten by a new value
+	EvictionReplaced
+	// EvictionCapacity means a Cache evicted the entry to stay within its capacity
+	EvictionCapacity
+)
+
+// String returns the name of the eviction reason
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionExpired:
+		return "expired"
+	case EvictionRemoved:
+		return "removed"
+	case EvictionReplaced:
+		return "replaced"
+	case EvictionCapacity:
+		return "capacity"
+	}
+	return "unknown"
+}
+
+// DefaultShardCount returns the shard count used when ConcurrentDictionaryOptions.ShardCount is not set: four shards per processor
+func DefaultShardCount() int {
+	return 4 * runtime.GOMAXPROCS(0)
 }
 
 // NewConcurrentDictionary creates a new dictionary
-func NewConcurrentDictionary[T any]() *ConcurrentDictionary[T] {
-	return &ConcurrentDictionary[T]{
-		values: NewDictionary[T](),
-		mutex:  sync.RWMutex{},
+func NewConcurrentDictionary[K comparable, V any]() *ConcurrentDictionary[K, V] {
+	return NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[K, V]{ShardCount: 1})
+}
+
+// NewConcurrentDictionaryWithOptions creates a new dictionary with the given options
+// options: The sharding, comparison and expiry options to use
+func NewConcurrentDictionaryWithOptions[K comparable, V any](options ConcurrentDictionaryOptions[K, V]) *ConcurrentDictionary[K, V] {
+	shardCount := options.ShardCount
+	if shardCount <= 0 {
+		shardCount = DefaultShardCount()
+	}
+	hash := options.Hash
+	if hash == nil {
+		seed := maphash.MakeSeed()
+		hash = func(key K) uint64 {
+			return maphash.Comparable(seed, key)
+		}
+	}
+	equals := options.Comparer
+	if equals == nil {
+		equals = Equals[V]
+	}
+	clock := options.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	shards := make([]*concurrentShard[K, V], shardCount)
+	for i := range shards {
+		shards[i] = &concurrentShard[K, V]{data: newShardData[K, V]()}
+	}
+	d := &ConcurrentDictionary[K, V]{
+		shards:     shards,
+		hash:       hash,
+		equals:     equals,
+		defaultTTL: max(options.DefaultTTL, 0),
+		clock:      clock,
+		onEvict:    options.OnEvict,
+	}
+	if d.defaultTTL > 0 {
+		d.expiring.Store(true)
 	}
+	if options.JanitorInterval > 0 {
+		d.stop = make(chan struct{})
+		go d.janitor(options.JanitorInterval)
+	}
+	return d
 }
 
-// ToConcurrentDictionary creates a new dictionary from a map
+// ToConcurrentDictionary creates a new dictionary from a copy of a map
 // m: The map to create the dictionary from
-func ToConcurrentDictionary[T any](m map[string]T) *ConcurrentDictionary[T] {
-	return &ConcurrentDictionary[T]{
-		values: ToDictionary[T](m),
-		mutex:  sync.RWMutex{},
+func ToConcurrentDictionary[K comparable, V any](m map[K]V) *ConcurrentDictionary[K, V] {
+	d := NewConcurrentDictionary[K, V]()
+	for key, value := range m {
+		d.shards[0].put(key, value, time.Time{})
 	}
+	return d
 }
 
-// Get returns the value in the dictionary for the given key
+// Get returns the value in the dictionary for the given key. An expired entry is removed and reported as missing
 // key: The key to get the value for
-func (d *ConcurrentDictionary[T]) Get(key string) (T, bool) {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.Get(key)
+func (d *ConcurrentDictionary[K, V]) Get(key K) (V, bool) {
+	shard := d.shard(key)
+	now := d.now()
+	shard.mutex.RLock()
+	value, ok := shard.data.lookup(key)
+	expired := ok && shard.data.expired(key, now)
+	shard.mutex.RUnlock()
+
+	if expired {
+		d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+			d.load(shard, key, now, evicted)
+		})
+		var zero V
+		return zero, false
+	}
+	return value, ok
 }
 
-// Set sets the value in the dictionary for the given key
+// Set sets the value in the dictionary for the given key. The entry expires after the default TTL, if there is one
 // key: The key to set the value for
 // value: The value to set
-func (d *ConcurrentDictionary[T]) Set(key string, value T) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.Set(key, value)
+func (d *ConcurrentDictionary[K, V]) Set(key K, value V) {
+	d.SetWithTTL(key, value, d.defaultTTL)
+}
+
+// SetWithTTL sets the value in the dictionary for the given key and makes it expire after ttl. A ttl of zero or less never expires
+// key: The key to set the value for
+// value: The value to set
+// ttl: How long the entry lives
+func (d *ConcurrentDictionary[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if ttl > 0 {
+		d.expiring.Store(true)
+	}
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		d.store(shard, key, value, ttl, now, evicted)
+	})
 }
 
 // Keys returns the keys in the dictionary
-func (d *ConcurrentDictionary[T]) Keys() []string {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.Keys()
+func (d *ConcurrentDictionary[K, V]) Keys() []K {
+	keys := []K{}
+	for key := range d.All() {
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 // ContainsKey returns if the dictionary contains the given key
 // key: The key to check for
-func (d *ConcurrentDictionary[T]) ContainsKey(key string) bool {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ContainsKey(key)
+func (d *ConcurrentDictionary[K, V]) ContainsKey(key K) bool {
+	_, ok := d.Get(key)
+	return ok
 }
 
 // ContainsValue returns if the dictionary contains the given value
 // value: The value to check for
-func (d *ConcurrentDictionary[T]) ContainsValue(value T) bool {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ContainsValue(value)
+func (d *ConcurrentDictionary[K, V]) ContainsValue(value V) bool {
+	return d.ContainsWhere(func(_ K, v V) bool {
+		return d.equals(v, value)
+	})
 }
 
 // ContainsWhere returns if the dictionary contains a value that satisfies the given predicate
 // fn: The predicate to check for
-func (d *ConcurrentDictionary[T]) ContainsWhere(fn func(string, T) bool) bool {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ContainsWhere(fn)
+func (d *ConcurrentDictionary[K, V]) ContainsWhere(fn func(K, V) bool) bool {
+	for key, value := range d.All() {
+		if fn(key, value) {
+			return true
+		}
+	}
+	return false
 }
 
 // Remove removes the value in the dictionary for the given key
 // key: The key to remove the value for
-func (d *ConcurrentDictionary[T]) Remove(key string) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.Remove(key)
+func (d *ConcurrentDictionary[K, V]) Remove(key K) {
+	d.GetAndDelete(key)
 }
 
 // RemoveValue removes the given value from the dictionary
 // value: The value to remove
-func (d *ConcurrentDictionary[T]) RemoveValue(value T) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.RemoveValue(value)
+func (d *ConcurrentDictionary[K, V]) RemoveValue(value V) {
+	d.RemoveWhere(func(_ K, v V) bool {
+		return d.equals(v, value)
+	})
 }
 
-// RemoveWhere removes the value in the dictionary that satisfies the given predicate
+// RemoveWhere removes the value in the dictionary that satisfies the given predicate. Expired entries are removed as well
 // fn: The predicate to check for
-func (d *ConcurrentDictionary[T]) RemoveWhere(fn func(string, T) bool) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.RemoveWhere(fn)
+func (d *ConcurrentDictionary[K, V]) RemoveWhere(fn func(K, V) bool) {
+	for _, shard := range d.shards {
+		d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+			for key := range shard.data.index {
+				value, _ := shard.data.lookup(key)
+				if shard.data.expired(key, now) {
+					shard.delete(key)
+					d.record(evicted, key, value, EvictionExpired)
+				} else if fn(key, value) {
+					shard.delete(key)
+					d.record(evicted, key, value, EvictionRemoved)
+				}
+			}
+		})
+	}
+}
+
+// DeleteExpired removes every expired entry and returns the number removed. The janitor calls this on each tick
+func (d *ConcurrentDictionary[K, V]) DeleteExpired() int {
+	removed := 0
+	for _, shard := range d.shards {
+		d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+			for key := range shard.data.expires {
+				if _, ok := d.load(shard, key, now, evicted); !ok {
+					removed++
+				}
+			}
+		})
+	}
+	return removed
 }
 
 // Clear removes all values from the dictionary
-func (d *ConcurrentDictionary[T]) Clear() {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.Clear()
+func (d *ConcurrentDictionary[K, V]) Clear() {
+	for _, shard := range d.shards {
+		d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+			if d.onEvict != nil {
+				for key := range shard.data.index {
+					value, _ := shard.data.lookup(key)
+					reason := EvictionRemoved
+					if shard.data.expired(key, now) {
+						reason = EvictionExpired
+					}
+					d.record(evicted, key, value, reason)
+				}
+			}
+			shard.reset()
+		})
+	}
 }
 
-// Count returns the number of values in the dictionary
-func (d *ConcurrentDictionary[T]) Count() int {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.Count()
+// Count returns the number of values in the dictionary. Expired entries are not counted, like every other read.
+// Only the entries that have a time to live are checked, so shards without any take O(1) time
+func (d *ConcurrentDictionary[K, V]) Count() int {
+	count := 0
+	now := d.now()
+	for _, shard := range d.shards {
+		count += shard.count(now)
+	}
+	return count
 }
 
 // ToArray returns the values in the dictionary as an array
-func (d *ConcurrentDictionary[T]) ToArray() []T {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ToArray()
+func (d *ConcurrentDictionary[K, V]) ToArray() []V {
+	items := []V{}
+	for _, value := range d.All() {
+		items = append(items, value)
+	}
+	return items
 }
 
 // ToList returns the values in the dictionary as a list
-func (d *ConcurrentDictionary[T]) ToList() List[T] {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ToList() // Use values.ToList() instead of ToArray()
+func (d *ConcurrentDictionary[K, V]) ToList() List[V] {
+	return d.ToArray()
 }
 
-// ToMap returns the dictionary as a map
-func (d *ConcurrentDictionary[T]) ToMap() map[string]T {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-	return d.values.ToMap()
+// ToMap returns a copy of the dictionary as a map
+func (d *ConcurrentDictionary[K, V]) ToMap() map[K]V {
+	m := make(map[K]V)
+	for key, value := range d.All() {
+		m[key] = value
+	}
+	return m
 }
 
-// Merge merges the given dictionaries into the dictionary
+// Merge merges the given dictionaries into the dictionary as a single atomic write, like MergeMaps.
+// Each source dictionary is read with ToMap first, so it is only weakly consistent if it is changed during the merge
 // dicts: The dictionaries to merge
-func (d *ConcurrentDictionary[T]) Merge(dicts ...*ConcurrentDictionary[T]) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	for _, dict := range dicts {
-		dict.mutex.RLock()
-		d.values.Merge(*dict.values)
-		dict.mutex.RUnlock()
+func (d *ConcurrentDictionary[K, V]) Merge(dicts ...*ConcurrentDictionary[K, V]) {
+	maps := make([]map[K]V, len(dicts))
+	for i, dict := range dicts {
+		maps[i] = dict.ToMap()
 	}
+	d.MergeMaps(maps...)
 }
 
-// MergeMaps merges the given maps into the dictionary
+// MergeMaps merges the given maps into the dictionary as a single atomic write.
+// Every shard the maps write to is locked, in shard order, before any value is set, so a Snapshot sees all of the merge or none of it
 // maps: The maps to merge
-func (d *ConcurrentDictionary[T]) MergeMaps(maps ...map[string]T) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.values.MergeMaps(maps...)
+func (d *ConcurrentDictionary[K, V]) MergeMaps(maps ...map[K]V) {
+	affected := make([]bool, len(d.shards))
+	for _, m := range maps {
+		for key := range m {
+			affected[d.shardIndex(key)] = true
+		}
+	}
+
+	var evicted []eviction[K, V]
+	func() {
+		for i, shard := range d.shards {
+			if affected[i] {
+				shard.mutex.Lock()
+				defer shard.mutex.Unlock()
+			}
+		}
+		now := d.now()
+		for _, m := range maps {
+			for key, value := range m {
+				d.store(d.shard(key), key, value, d.defaultTTL, now, &evicted)
+			}
+		}
+	}()
+	for _, e := range evicted {
+		d.onEvict(e.key, e.value, e.reason)
+	}
+}
+
+// All returns a weakly consistent iterator over the key-value pairs in the dictionary.
+// Each shard is read under its read lock a small batch at a time, so stopping early skips the rest of the work and writers only wait for one batch.
+// Every entry present for the whole iteration is yielded once. Entries added after iteration reaches their shard are not yielded,
+// and an entry removed or changed during iteration may be yielded as it was.
+// No lock is held while the loop body runs, so the body may read or write the dictionary
+func (d *ConcurrentDictionary[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, shard := range d.shards {
+			if !shard.all(d.now(), yield) {
+				return
+			}
+		}
+	}
+}
+
+// Range calls fn for each key-value pair in the dictionary until fn returns false. It has the same consistency as All
+// fn: The function to call for each pair
+func (d *ConcurrentDictionary[K, V]) Range(fn func(K, V) bool) {
+	for key, value := range d.All() {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Snapshot returns an immutable view of the dictionary at a single point in time.
+// It copies no entries: it holds every shard's write lock just long enough to record each shard's version, so it takes O(shards) time.
+// Each later write saves the entry it changes the first time, so a write never copies more than one entry, and once the snapshot
+// is no longer referenced writes stop saving entries. Use All for a weakly consistent view that saves nothing
+func (d *ConcurrentDictionary[K, V]) Snapshot() *ConcurrentDictionarySnapshot[K, V] {
+	for _, shard := range d.shards {
+		shard.mutex.Lock()
+	}
+	snapshot := &ConcurrentDictionarySnapshot[K, V]{
+		shards:   d.shards,
+		versions: make([]*shardVersion[K, V], len(d.shards)),
+		hash:     d.hash,
+		equals:   d.equals,
+		now:      d.now(),
+	}
+	for i, shard := range d.shards {
+		snapshot.versions[i] = shard.snapshot()
+	}
+	for _, shard := range d.shards {
+		shard.mutex.Unlock()
+	}
+	return snapshot
+}
+
+// GetOrAdd returns the value for the key if it is present. Otherwise it adds the value returned by the factory and returns that.
+// The factory runs while the key's shard is locked, so it is called at most once per missing key but must not use the dictionary.
+// The boolean is true if the value was already present
+// key: The key to get or add
+// factory: The function that creates the value for a missing key
+func (d *ConcurrentDictionary[K, V]) GetOrAdd(key K, factory func(K) V) (V, bool) {
+	if value, ok := d.Get(key); ok {
+		return value, true
+	}
+
+	var value V
+	loaded := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		if value, loaded = d.load(shard, key, now, evicted); loaded {
+			return
+		}
+		value = factory(key)
+		d.store(shard, key, value, d.defaultTTL, now, evicted)
+	})
+	return value, loaded
+}
+
+// AddOrUpdate adds the value if the key is missing, or replaces the current value with the result of updateFn. It returns the new value.
+// updateFn runs while the key's shard is locked and must not use the dictionary
+// key: The key to add or update
+// addValue: The value to add if the key is missing
+// updateFn: The function that computes the new value from the key and current value
+func (d *ConcurrentDictionary[K, V]) AddOrUpdate(key K, addValue V, updateFn func(K, V) V) V {
+	value := addValue
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		if current, ok := d.load(shard, key, now, evicted); ok {
+			value = updateFn(key, current)
+		}
+		d.store(shard, key, value, d.defaultTTL, now, evicted)
+	})
+	return value
+}
+
+// TryAdd adds the value if the key is missing. It returns false if the key was already present
+// key: The key to add
+// value: The value to add
+func (d *ConcurrentDictionary[K, V]) TryAdd(key K, value V) bool {
+	added := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		if _, ok := d.load(shard, key, now, evicted); ok {
+			return
+		}
+		d.store(shard, key, value, d.defaultTTL, now, evicted)
+		added = true
+	})
+	return added
+}
+
+// TryUpdate replaces the value for the key only if the current value equals expected, using the dictionary's Comparer.
+// It returns false if the key is missing or holds a different value
+// key: The key to update
+// newValue: The value to set
+// expected: The value the key must currently hold
+func (d *ConcurrentDictionary[K, V]) TryUpdate(key K, newValue V, expected V) bool {
+	updated := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		current, ok := d.load(shard, key, now, evicted)
+		if !ok || !d.equals(current, expected) {
+			return
+		}
+		d.store(shard, key, newValue, d.defaultTTL, now, evicted)
+		updated = true
+	})
+	return updated
+}
+
+// Compute atomically replaces the entry for the key with the result of fn.
+// fn receives the current value and whether the key is present, and returns the new value and whether to keep it;
+// returning false removes the key. Compute returns the value now stored and whether the key is present.
+// fn runs while the key's shard is locked and must not use the dictionary
+// key: The key to compute
+// fn: The function that computes the new entry
+func (d *ConcurrentDictionary[K, V]) Compute(key K, fn func(value V, exists bool) (V, bool)) (V, bool) {
+	var value V
+	keep := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		current, exists := d.load(shard, key, now, evicted)
+		value, keep = fn(current, exists)
+		if keep {
+			d.store(shard, key, value, d.defaultTTL, now, evicted)
+			return
+		}
+		var zero V
+		value = zero
+		if exists {
+			shard.delete(key)
+			d.record(evicted, key, current, EvictionRemoved)
+		}
+	})
+	return value, keep
+}
+
+// GetAndDelete removes the key and returns the value it held, or false if it was missing
+// key: The key to remove
+func (d *ConcurrentDictionary[K, V]) GetAndDelete(key K) (V, bool) {
+	var value V
+	found := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		if value, found = d.load(shard, key, now, evicted); found {
+			shard.delete(key)
+			d.record(evicted, key, value, EvictionRemoved)
+		}
+	})
+	return value, found
+}
+
+// Swap sets the value for the key and returns the previous value, or false if the key was missing
+// key: The key to set
+// value: The value to set
+func (d *ConcurrentDictionary[K, V]) Swap(key K, value V) (V, bool) {
+	var previous V
+	loaded := false
+	shard := d.shard(key)
+	d.write(shard, func(now time.Time, evicted *[]eviction[K, V]) {
+		previous, loaded = d.load(shard, key, now, evicted)
+		d.store(shard, key, value, d.defaultTTL, now, evicted)
+	})
+	return previous, loaded
+}
+
+// Close stops the background janitor, if there is one. The dictionary can still be used afterwards; expired entries are then removed lazily
+func (d *ConcurrentDictionary[K, V]) Close() {
+	if d.stop == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+}
+
+// ShardCount returns the number of independently locked shards in the dictionary
+func (d *ConcurrentDictionary[K, V]) ShardCount() int {
+	return len(d.shards)
+}
+
+func (d *ConcurrentDictionary[K, V]) shard(key K) *concurrentShard[K, V] {
+	return d.shards[d.shardIndex(key)]
+}
+
+func (d *ConcurrentDictionary[K, V]) shardIndex(key K) int {
+	if len(d.shards) == 1 {
+		return 0
+	}
+	return int(d.hash(key) % uint64(len(d.shards)))
+}
+
+// now returns the current time for expiry checks, or the zero time if no entry has ever been given a TTL
+func (d *ConcurrentDictionary[K, V]) now() time.Time {
+	if !d.expiring.Load() {
+		return time.Time{}
+	}
+	return d.clock()
+}
+
+// write runs fn under the shard's write lock, then reports the entries fn evicted to OnEvict once the lock is released
+func (d *ConcurrentDictionary[K, V]) write(shard *concurrentShard[K, V], fn func(now time.Time, evicted *[]eviction[K, V])) {
+	var evicted []eviction[K, V]
+	func() {
+		shard.mutex.Lock()
+		defer shard.mutex.Unlock()
+		fn(d.now(), &evicted)
+	}()
+	for _, e := range evicted {
+		d.onEvict(e.key, e.value, e.reason)
+	}
+}
+
+// record adds an evicted entry to be reported to OnEvict. It does nothing when there is no OnEvict callback
+func (d *ConcurrentDictionary[K, V]) record(evicted *[]eviction[K, V], key K, value V, reason EvictionReason) {
+	if d.onEvict != nil {
+		*evicted = append(*evicted, eviction[K, V]{key: key, value: value, reason: reason})
+	}
+}
+
+// load returns the value for the key, removing it instead if it has expired. The caller must hold the shard's write lock
+func (d *ConcurrentDictionary[K, V]) load(shard *concurrentShard[K, V], key K, now time.Time, evicted *[]eviction[K, V]) (V, bool) {
+	value, ok := shard.data.lookup(key)
+	if ok && shard.data.expired(key, now) {
+		shard.delete(key)
+		d.record(evicted, key, value, EvictionExpired)
+		var zero V
+		return zero, false
+	}
+	return value, ok
+}
+
+// store sets the value for the key with the given time to live, reporting the value it replaces. The caller must hold the shard's write lock
+func (d *ConcurrentDictionary[K, V]) store(shard *concurrentShard[K, V], key K, value V, ttl time.Duration, now time.Time, evicted *[]eviction[K, V]) {
+	if previous, ok := d.load(shard, key, now, evicted); ok {
+		d.record(evicted, key, previous, EvictionReplaced)
+	}
+	var expiry time.Time
+	if ttl > 0 {
+		expiry = now.Add(ttl)
+	}
+	shard.put(key, value, expiry)
+}
+
+func (d *ConcurrentDictionary[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.DeleteExpired()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// ConcurrentDictionarySnapshot is an immutable, point-in-time view of a ConcurrentDictionary created with Snapshot.
+// It is safe to use from multiple goroutines and never changes, whatever happens to the dictionary afterwards.
+// It reads the dictionary's shards as they were when it was taken, under their read locks a small batch at a time, like ConcurrentDictionary.All.
+// Entries that had expired when the snapshot was taken are not part of it
+type ConcurrentDictionarySnapshot[K comparable, V any] struct {
+	shards   []*concurrentShard[K, V]
+	versions []*shardVersion[K, V]
+	hash     func(K) uint64
+	equals   func(V, V) bool
+	now      time.Time
+	// count is computed the first time Count is called
+	count     int
+	countOnce sync.Once
+}
+
+// Get returns the value in the snapshot for the given key
+// key: The key to get the value for
+func (s *ConcurrentDictionarySnapshot[K, V]) Get(key K) (V, bool) {
+	i := s.shardIndex(key)
+	return s.shards[i].getAt(s.versions[i], key, s.now)
+}
+
+// ContainsKey returns if the snapshot contains the given key
+// key: The key to check for
+func (s *ConcurrentDictionarySnapshot[K, V]) ContainsKey(key K) bool {
+	_, ok := s.Get(key)
+	return ok
+}
+
+// ContainsValue returns if the snapshot contains the given value
+// value: The value to check for
+func (s *ConcurrentDictionarySnapshot[K, V]) ContainsValue(value V) bool {
+	return s.ContainsWhere(func(_ K, v V) bool {
+		return s.equals(v, value)
+	})
+}
+
+// ContainsWhere returns if the snapshot contains a value that satisfies the given predicate
+// fn: The predicate to check for
+func (s *ConcurrentDictionarySnapshot[K, V]) ContainsWhere(fn func(K, V) bool) bool {
+	for key, value := range s.All() {
+		if fn(key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of values in the snapshot. The first call counts them, which takes O(n) time
+func (s *ConcurrentDictionarySnapshot[K, V]) Count() int {
+	s.countOnce.Do(func() {
+		for range s.All() {
+			s.count++
+		}
+	})
+	return s.count
+}
+
+// Keys returns the keys in the snapshot
+func (s *ConcurrentDictionarySnapshot[K, V]) Keys() []K {
+	keys := []K{}
+	for key := range s.All() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ToArray returns the values in the snapshot as an array
+func (s *ConcurrentDictionarySnapshot[K, V]) ToArray() []V {
+	values := []V{}
+	for _, value := range s.All() {
+		values = append(values, value)
+	}
+	return values
+}
+
+// ToList returns the values in the snapshot as a list
+func (s *ConcurrentDictionarySnapshot[K, V]) ToList() List[V] {
+	return s.ToArray()
+}
+
+// ToMap returns a copy of the snapshot as a map
+func (s *ConcurrentDictionarySnapshot[K, V]) ToMap() map[K]V {
+	m := make(map[K]V)
+	for key, value := range s.All() {
+		m[key] = value
+	}
+	return m
+}
+
+// All returns an iterator over the key-value pairs in the snapshot
+func (s *ConcurrentDictionarySnapshot[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for i, shard := range s.shards {
+			if !shard.allAt(s.versions[i], s.now, yield) {
+				return
+			}
+		}
+	}
+}
+
+// Range calls fn for each key-value pair in the snapshot until fn returns false
+// fn: The function to call for each pair
+func (s *ConcurrentDictionarySnapshot[K, V]) Range(fn func(K, V) bool) {
+	for key, value := range s.All() {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (s *ConcurrentDictionarySnapshot[K, V]) shardIndex(key K) int {
+	if len(s.shards) == 1 {
+		return 0
+	}
+	return int(s.hash(key) % uint64(len(s.shards)))
+}
+
+// ConcurrentStringDictionary is a ConcurrentDictionary keyed by strings, the only key type supported before
+// ConcurrentDictionary took a key type parameter
+type ConcurrentStringDictionary[V any] = ConcurrentDictionary[string, V]
+
+// NewConcurrentStringDictionary creates a new concurrent dictionary keyed by strings
+func NewConcurrentStringDictionary[V any]() *ConcurrentStringDictionary[V] {
+	return NewConcurrentDictionary[string, V]()
 }
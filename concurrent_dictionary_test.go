@@ -1,8 +1,14 @@
 package ectolinq
 
 import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"runtime"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,7 +16,7 @@ import (
 
 func TestConcurrentDictionary(t *testing.T) {
 	t.Run("NewConcurrentDictionary", func(t *testing.T) {
-		d := NewConcurrentDictionary[int]()
+		d := NewConcurrentDictionary[string, int]()
 		require.NotNil(t, d, "NewConcurrentDictionary should not return nil")
 	})
 
@@ -21,7 +27,7 @@ func TestConcurrentDictionary(t *testing.T) {
 	})
 
 	t.Run("Get and Set", func(t *testing.T) {
-		d := NewConcurrentDictionary[int]()
+		d := NewConcurrentDictionary[string, int]()
 		d.Set("key", 42)
 		value, ok := d.Get("key")
 		assert.True(t, ok, "Get should return true for existing key")
@@ -108,7 +114,7 @@ func TestConcurrentDictionary(t *testing.T) {
 	})
 
 	t.Run("Concurrent access", func(t *testing.T) {
-		d := NewConcurrentDictionary[int]()
+		d := NewConcurrentDictionary[string, int]()
 		var wg sync.WaitGroup
 		for i := 0; i < 1000; i++ {
 			wg.Add(1)
@@ -120,4 +126,839 @@ func TestConcurrentDictionary(t *testing.T) {
 		wg.Wait()
 		assert.Equal(t, 1000, d.Count(), "Concurrent Set operations should result in 1000 elements")
 	})
+
+	t.Run("Non-string keys", func(t *testing.T) {
+		d := NewConcurrentDictionary[int, string]()
+		d.Set(1, "one")
+		d.MergeMaps(map[int]string{2: "two"})
+		d.Merge(ToConcurrentDictionary(map[int]string{3: "three"}))
+		assert.Equal(t, 3, d.Count(), "Int keys should be supported")
+		assert.ElementsMatch(t, []int{1, 2, 3}, d.Keys())
+		d.RemoveWhere(func(k int, _ string) bool { return k > 1 })
+		assert.Equal(t, map[int]string{1: "one"}, d.ToMap())
+	})
+
+	t.Run("ConcurrentStringDictionary", func(t *testing.T) {
+		var d *ConcurrentStringDictionary[int] = NewConcurrentStringDictionary[int]()
+		d.Set("key", 42)
+		value, ok := d.Get("key")
+		assert.True(t, ok, "ConcurrentStringDictionary should behave like ConcurrentDictionary[string, V]")
+		assert.Equal(t, 42, value)
+	})
+
+	t.Run("ToMap returns a copy", func(t *testing.T) {
+		d := ToConcurrentDictionary(map[string]int{"a": 1})
+		m := d.ToMap()
+		m["b"] = 2
+		assert.False(t, d.ContainsKey("b"), "Changing the returned map should not change the dictionary")
+	})
+}
+
+func TestShardedConcurrentDictionary(t *testing.T) {
+	newSharded := func() *ConcurrentDictionary[int, int] {
+		return NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{ShardCount: 8})
+	}
+
+	t.Run("Options", func(t *testing.T) {
+		assert.Equal(t, 1, NewConcurrentDictionary[int, int]().ShardCount())
+		assert.Equal(t, 8, newSharded().ShardCount())
+		assert.Equal(t, DefaultShardCount(), NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{}).ShardCount())
+	})
+
+	t.Run("Cross-shard operations", func(t *testing.T) {
+		d := newSharded()
+		expected := make(map[int]int)
+		for i := 0; i < 100; i++ {
+			d.Set(i, i*10)
+			expected[i] = i * 10
+		}
+
+		assert.Equal(t, 100, d.Count())
+		assert.Len(t, d.Keys(), 100)
+		assert.Len(t, d.ToArray(), 100)
+		assert.Equal(t, expected, d.ToMap())
+		assert.True(t, d.ContainsValue(990))
+		assert.True(t, d.ContainsWhere(func(k int, v int) bool { return k == 50 && v == 500 }))
+
+		d.RemoveWhere(func(k int, _ int) bool { return k%2 == 0 })
+		assert.Equal(t, 50, d.Count())
+		assert.False(t, d.ContainsKey(10))
+		assert.True(t, d.ContainsKey(11))
+
+		d.RemoveValue(110)
+		assert.False(t, d.ContainsKey(11))
+
+		d.Clear()
+		assert.Equal(t, 0, d.Count())
+		assert.Empty(t, d.Keys())
+	})
+
+	t.Run("Merge between shard layouts", func(t *testing.T) {
+		d := newSharded()
+		d.MergeMaps(map[int]int{1: 1, 2: 2})
+		d.Merge(ToConcurrentDictionary(map[int]int{3: 3}))
+		single := NewConcurrentDictionary[int, int]()
+		single.Merge(d)
+		assert.Equal(t, map[int]int{1: 1, 2: 2, 3: 3}, single.ToMap())
+	})
+
+	t.Run("Merges are atomic", func(t *testing.T) {
+		d := newSharded()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for generation := 0; generation < 50; generation++ {
+				m := make(map[int]int, 100)
+				for i := 0; i < 100; i++ {
+					m[i] = generation
+				}
+				d.MergeMaps(m)
+			}
+		}()
+		for i := 0; i < 50; i++ {
+			values := Distinct(d.Snapshot().ToArray())
+			assert.LessOrEqual(t, len(values), 1, "A snapshot should see all of a merge or none of it, got %v", values)
+		}
+		wg.Wait()
+		assert.Equal(t, 100, d.Count())
+	})
+
+	t.Run("Custom hash", func(t *testing.T) {
+		var calls sync.Map
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, int]{
+			ShardCount: 4,
+			Hash: func(key string) uint64 {
+				calls.Store(key, true)
+				return uint64(len(key))
+			},
+		})
+		d.Set("abc", 1)
+		value, ok := d.Get("abc")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+		_, called := calls.Load("abc")
+		assert.True(t, called, "The custom hash should pick the shard")
+	})
+
+	t.Run("Concurrent access", func(t *testing.T) {
+		d := newSharded()
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					key := g*500 + i
+					d.Set(key, key)
+					d.Get(key)
+					if i%10 == 0 {
+						d.Count()
+						d.Keys()
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		assert.Equal(t, 8000, d.Count())
+		d.RemoveWhere(func(k int, _ int) bool { return k >= 4000 })
+		assert.Equal(t, 4000, d.Count())
+	})
+}
+
+// benchmarkConcurrentDictionary runs b.N operations split across the given number of goroutines.
+// One in writeEvery operations is a Set; the rest are Gets
+func benchmarkConcurrentDictionary(b *testing.B, d *ConcurrentDictionary[int, int], goroutines int, writeEvery int) {
+	const keySpace = 1 << 16
+	for i := 0; i < keySpace; i++ {
+		d.Set(i, i)
+	}
+
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			key := g * 7919
+			for i := g; i < b.N; i += goroutines {
+				key = (key + 40503) % keySpace
+				if i%writeEvery == 0 {
+					d.Set(key, i)
+				} else {
+					d.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkConcurrentDictionary(b *testing.B) {
+	workloads := []struct {
+		name       string
+		writeEvery int
+	}{
+		{"writes", 1},
+		{"mixed", 10},
+	}
+	layouts := []struct {
+		name   string
+		shards int
+	}{
+		{"single-lock", 1},
+		{"sharded", 0},
+	}
+
+	for _, workload := range workloads {
+		for _, goroutines := range []int{8, 16, 32, 64} {
+			for _, layout := range layouts {
+				name := fmt.Sprintf("%s/goroutines=%d/%s", workload.name, goroutines, layout.name)
+				b.Run(name, func(b *testing.B) {
+					d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{ShardCount: layout.shards})
+					benchmarkConcurrentDictionary(b, d, goroutines, workload.writeEvery)
+				})
+			}
+		}
+	}
+}
+
+func TestConcurrentDictionaryAtomicOperations(t *testing.T) {
+	t.Run("GetOrAdd", func(t *testing.T) {
+		d := NewConcurrentDictionary[string, int]()
+		value, loaded := d.GetOrAdd("a", func(string) int { return 1 })
+		assert.False(t, loaded)
+		assert.Equal(t, 1, value)
+		value, loaded = d.GetOrAdd("a", func(string) int { return 2 })
+		assert.True(t, loaded, "GetOrAdd should return the existing value")
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("GetOrAdd calls the factory once per key", func(t *testing.T) {
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{ShardCount: 4})
+		var mutex sync.Mutex
+		calls := make(map[int]int)
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for key := 0; key < 100; key++ {
+					d.GetOrAdd(key, func(k int) int {
+						mutex.Lock()
+						defer mutex.Unlock()
+						calls[k]++
+						return k * k
+					})
+				}
+			}()
+		}
+		wg.Wait()
+		for key := 0; key < 100; key++ {
+			assert.Equal(t, 1, calls[key], "The factory should run once for key %d", key)
+		}
+	})
+
+	t.Run("AddOrUpdate", func(t *testing.T) {
+		d := NewConcurrentDictionary[string, int]()
+		increment := func(_ string, v int) int { return v + 1 }
+		assert.Equal(t, 1, d.AddOrUpdate("hits", 1, increment))
+		assert.Equal(t, 2, d.AddOrUpdate("hits", 1, increment))
+	})
+
+	t.Run("AddOrUpdate counters under contention", func(t *testing.T) {
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, int]{ShardCount: 4})
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					d.AddOrUpdate(fmt.Sprint(i%10), 1, func(_ string, v int) int { return v + 1 })
+				}
+			}()
+		}
+		wg.Wait()
+		for _, count := range d.ToArray() {
+			assert.Equal(t, 1600, count, "No increments should be lost")
+		}
+	})
+
+	t.Run("TryAdd", func(t *testing.T) {
+		d := NewConcurrentDictionary[string, int]()
+		assert.True(t, d.TryAdd("a", 1))
+		assert.False(t, d.TryAdd("a", 2), "TryAdd should not replace an existing value")
+		value, _ := d.Get("a")
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("TryUpdate", func(t *testing.T) {
+		d := ToConcurrentDictionary(map[string]int{"a": 1})
+		assert.False(t, d.TryUpdate("a", 3, 2), "TryUpdate should fail when the value does not match")
+		assert.True(t, d.TryUpdate("a", 3, 1))
+		assert.False(t, d.TryUpdate("missing", 1, 0), "TryUpdate should fail for a missing key")
+		value, _ := d.Get("a")
+		assert.Equal(t, 3, value)
+	})
+
+	t.Run("TryUpdate with a comparer", func(t *testing.T) {
+		type account struct {
+			ID      int
+			Balance int
+		}
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, account]{
+			ShardCount: 1,
+			Comparer:   func(a account, b account) bool { return a.ID == b.ID },
+		})
+		d.Set("alice", account{ID: 1, Balance: 10})
+		assert.True(t, d.TryUpdate("alice", account{ID: 1, Balance: 20}, account{ID: 1}), "The comparer should decide equality")
+		assert.False(t, d.TryUpdate("alice", account{ID: 2}, account{ID: 2}))
+		assert.True(t, d.ContainsValue(account{ID: 1}))
+		d.RemoveValue(account{ID: 1})
+		assert.Equal(t, 0, d.Count())
+	})
+
+	t.Run("Compute", func(t *testing.T) {
+		d := NewConcurrentDictionary[string, int]()
+		value, ok := d.Compute("a", func(v int, exists bool) (int, bool) {
+			assert.False(t, exists)
+			return 5, true
+		})
+		assert.True(t, ok)
+		assert.Equal(t, 5, value)
+
+		value, ok = d.Compute("a", func(v int, exists bool) (int, bool) {
+			assert.True(t, exists)
+			return v * 2, true
+		})
+		assert.True(t, ok)
+		assert.Equal(t, 10, value)
+
+		_, ok = d.Compute("a", func(int, bool) (int, bool) { return 0, false })
+		assert.False(t, ok)
+		assert.False(t, d.ContainsKey("a"), "Compute should remove the key when fn returns false")
+	})
+
+	t.Run("GetAndDelete", func(t *testing.T) {
+		d := ToConcurrentDictionary(map[string]int{"a": 1})
+		value, ok := d.GetAndDelete("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+		assert.False(t, d.ContainsKey("a"))
+		_, ok = d.GetAndDelete("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		d := NewConcurrentDictionary[string, int]()
+		_, loaded := d.Swap("a", 1)
+		assert.False(t, loaded)
+		previous, loaded := d.Swap("a", 2)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, previous)
+		value, _ := d.Get("a")
+		assert.Equal(t, 2, value)
+	})
+}
+
+func TestConcurrentDictionaryIteration(t *testing.T) {
+	layouts := map[string]func() *ConcurrentDictionary[int, int]{
+		"single-lock": NewConcurrentDictionary[int, int],
+		"sharded": func() *ConcurrentDictionary[int, int] {
+			return NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{ShardCount: 8})
+		},
+	}
+
+	for name, newDictionary := range layouts {
+		t.Run(name, func(t *testing.T) {
+			t.Run("All", func(t *testing.T) {
+				d := newDictionary()
+				d.MergeMaps(map[int]int{1: 10, 2: 20, 3: 30})
+				seen := make(map[int]int)
+				for key, value := range d.All() {
+					seen[key] = value
+				}
+				assert.Equal(t, map[int]int{1: 10, 2: 20, 3: 30}, seen)
+			})
+
+			t.Run("Range stops early", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 100; i++ {
+					d.Set(i, i)
+				}
+				calls := 0
+				d.Range(func(int, int) bool {
+					calls++
+					return calls < 5
+				})
+				assert.Equal(t, 5, calls)
+			})
+
+			t.Run("Loop body can write to the dictionary", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 100; i++ {
+					d.Set(i, i)
+				}
+				for key, value := range d.All() {
+					d.Set(key, value*2)
+					d.Remove(key + 1000)
+				}
+				for key, value := range d.ToMap() {
+					assert.Equal(t, key*2, value)
+				}
+			})
+
+			t.Run("Reads and snapshots do not make writes copy the shard", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 100; i++ {
+					d.Set(i, i)
+				}
+				data := Map(d.shards, func(shard *concurrentShard[int, int]) *shardData[int, int] { return shard.data })
+
+				d.Count()
+				d.ToMap()
+				d.Keys()
+				for range d.All() {
+				}
+				d.Snapshot()
+				for i := 0; i < 100; i++ {
+					d.Set(i, i+1)
+				}
+				for i, shard := range d.shards {
+					assert.Same(t, data[i], shard.data, "Expected writes to change shard %d in place", i)
+				}
+			})
+
+			t.Run("Stopping early does not read the rest of the dictionary", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 10000; i++ {
+					d.Set(i, i)
+				}
+				allocs := testing.AllocsPerRun(100, func() {
+					d.Range(func(int, int) bool { return false })
+				})
+				assert.LessOrEqual(t, allocs, 3.0, "Expected a fixed number of allocations, not one per entry")
+			})
+
+			t.Run("Entries present for the whole iteration are yielded once", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 1000; i++ {
+					d.Set(i, i)
+				}
+				seen := make(map[int]int)
+				for key := range d.All() {
+					seen[key]++
+					if key < 1000 && key%2 == 0 {
+						// Removing and re-adding keys leaves holes that are compacted while iteration is under way
+						for j := 1; j < 1000; j += 2 {
+							d.Remove(j)
+						}
+						for j := 1; j < 1000; j += 2 {
+							d.Set(j, j)
+						}
+						d.Set(key+1000, key)
+					}
+				}
+				for i := 0; i < 1000; i += 2 {
+					assert.Equal(t, 1, seen[i], "Key %d should be yielded exactly once", i)
+				}
+				for key, count := range seen {
+					assert.Equal(t, 1, count, "Key %d should not be yielded twice", key)
+				}
+			})
+
+			t.Run("Snapshot is immutable", func(t *testing.T) {
+				d := newDictionary()
+				d.MergeMaps(map[int]int{1: 10, 2: 20})
+				snapshot := d.Snapshot()
+
+				d.Set(1, 11)
+				d.Set(3, 30)
+				d.Remove(2)
+				d.Compute(1, func(v int, _ bool) (int, bool) { return v + 1, true })
+
+				assert.Equal(t, 2, snapshot.Count())
+				value, ok := snapshot.Get(1)
+				assert.True(t, ok)
+				assert.Equal(t, 10, value, "The snapshot should not see later writes")
+				assert.True(t, snapshot.ContainsKey(2))
+				assert.False(t, snapshot.ContainsKey(3))
+				assert.True(t, snapshot.ContainsValue(20))
+				assert.ElementsMatch(t, []int{1, 2}, snapshot.Keys())
+				assert.ElementsMatch(t, []int{10, 20}, snapshot.ToArray())
+				assert.Equal(t, map[int]int{1: 10, 2: 20}, snapshot.ToMap())
+
+				assert.Equal(t, map[int]int{1: 12, 3: 30}, d.ToMap(), "The dictionary should keep its own writes")
+
+				d.Clear()
+				assert.Equal(t, 2, snapshot.Count())
+				assert.Len(t, snapshot.ToList(), 2)
+			})
+
+			t.Run("Snapshots taken between writes each keep their own view", func(t *testing.T) {
+				d := newDictionary()
+				model := make(map[int]int)
+				rng := rand.New(rand.NewPCG(1, 2))
+				type taken struct {
+					snapshot *ConcurrentDictionarySnapshot[int, int]
+					expected map[int]int
+				}
+				var snapshots []taken
+				for step := 0; step < 5000; step++ {
+					key := rng.IntN(300)
+					switch n := rng.IntN(100); {
+					case n < 50:
+						d.Set(key, step)
+						model[key] = step
+					case n < 90:
+						d.Remove(key)
+						delete(model, key)
+					case n < 91:
+						d.Clear()
+						clear(model)
+					case n < 95:
+						snapshots = append(snapshots, taken{d.Snapshot(), maps.Clone(model)})
+					default:
+						if len(snapshots) > 0 {
+							// Dropping a snapshot lets its versions be collected while later snapshots still use theirs
+							snapshots = slices.Delete(snapshots, 0, 1)
+							runtime.GC()
+						}
+					}
+				}
+				for _, s := range snapshots {
+					assert.Equal(t, s.expected, s.snapshot.ToMap())
+					assert.Equal(t, len(s.expected), s.snapshot.Count())
+					for key := 0; key < 300; key++ {
+						value, ok := s.snapshot.Get(key)
+						expected, exists := s.expected[key]
+						assert.Equal(t, exists, ok)
+						assert.Equal(t, expected, value)
+					}
+				}
+				assert.Equal(t, model, d.ToMap())
+			})
+
+			t.Run("Snapshot does not copy the entries", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 100000; i++ {
+					d.Set(i, i)
+				}
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				for i := 0; i < 10; i++ {
+					d.Set(i, i)
+					d.Snapshot()
+				}
+				runtime.ReadMemStats(&after)
+				assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "Expected snapshots to allocate per shard, not per entry")
+			})
+
+			t.Run("Set does not wait while a large snapshot is taken and read", func(t *testing.T) {
+				d := newDictionary()
+				for i := 0; i < 100000; i++ {
+					d.Set(i, i)
+				}
+
+				reading := make(chan struct{})
+				release := make(chan struct{})
+				seen := make(chan map[int]int)
+				go func() {
+					m := make(map[int]int)
+					for key, value := range d.Snapshot().All() {
+						if len(m) == 0 {
+							close(reading)
+							<-release
+						}
+						m[key] = value
+					}
+					seen <- m
+				}()
+				<-reading
+
+				written := make(chan struct{})
+				go func() {
+					for i := 0; i < 100000; i++ {
+						d.Set(i, -i)
+					}
+					for i := 1; i < 100000; i += 2 {
+						d.Remove(i)
+					}
+					for i := 1; i < 100000; i += 4 {
+						d.Set(i, i)
+					}
+					d.Set(100000, 0)
+					close(written)
+				}()
+				select {
+				case <-written:
+				case <-time.After(10 * time.Second):
+					t.Fatal("Writes should not wait for a snapshot that is being read")
+				}
+				close(release)
+
+				m := <-seen
+				assert.Len(t, m, 100000)
+				for key, value := range m {
+					if key != value {
+						assert.Equal(t, key, value, "The snapshot should see key %d as it was when it was taken", key)
+						break
+					}
+				}
+			})
+		})
+	}
+
+	t.Run("Concurrent iteration and writes", func(t *testing.T) {
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[int, int]{ShardCount: 4})
+		for i := 0; i < 1000; i++ {
+			d.Set(i, 0)
+		}
+
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					d.AddOrUpdate(i, 1, func(_ int, v int) int { return v + 1 })
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 5; i++ {
+					snapshot := d.Snapshot()
+					count := 0
+					snapshot.Range(func(int, int) bool {
+						count++
+						return true
+					})
+					assert.Equal(t, 1000, count)
+					d.Range(func(int, int) bool { return true })
+					d.ToMap()
+				}
+			}()
+		}
+		wg.Wait()
+
+		for key, value := range d.All() {
+			assert.Equal(t, 4, value, "Key %d should have every increment", key)
+		}
+	})
+}
+
+// fakeClock is a manually advanced clock for testing expiry without sleeping
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// evictionLog records OnEvict calls
+type evictionLog[K comparable, V any] struct {
+	mutex   sync.Mutex
+	entries []string
+}
+
+func (l *evictionLog[K, V]) OnEvict(key K, value V, reason EvictionReason) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf("%v=%v %s", key, value, reason))
+}
+
+func (l *evictionLog[K, V]) Entries() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]string{}, l.entries...)
+}
+
+func TestConcurrentDictionaryTTL(t *testing.T) {
+	newTTLDictionary := func(defaultTTL time.Duration) (*ConcurrentDictionary[string, int], *fakeClock, *evictionLog[string, int]) {
+		clock := newFakeClock()
+		log := &evictionLog[string, int]{}
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, int]{
+			ShardCount: 4,
+			DefaultTTL: defaultTTL,
+			Clock:      clock.Now,
+			OnEvict:    log.OnEvict,
+		})
+		return d, clock, log
+	}
+
+	t.Run("SetWithTTL expires lazily on Get", func(t *testing.T) {
+		d, clock, log := newTTLDictionary(0)
+		d.SetWithTTL("session", 1, time.Minute)
+		d.Set("forever", 2)
+
+		clock.Advance(59 * time.Second)
+		_, ok := d.Get("session")
+		assert.True(t, ok, "The entry should live until its TTL runs out")
+
+		clock.Advance(time.Second)
+		_, ok = d.Get("session")
+		assert.False(t, ok, "The entry should expire once its TTL runs out")
+		assert.Equal(t, []string{"session=1 expired"}, log.Entries())
+		assert.True(t, d.ContainsKey("forever"))
+		assert.Equal(t, 1, d.Count())
+	})
+
+	t.Run("Default TTL applies to every write", func(t *testing.T) {
+		d, clock, _ := newTTLDictionary(time.Minute)
+		d.Set("a", 1)
+		d.TryAdd("b", 2)
+		d.GetOrAdd("c", func(string) int { return 3 })
+		d.SetWithTTL("d", 4, 0)
+
+		clock.Advance(30 * time.Second)
+		d.AddOrUpdate("a", 0, func(_ string, v int) int { return v + 1 })
+
+		clock.Advance(30 * time.Second)
+		assert.ElementsMatch(t, []string{"a", "d"}, d.Keys(), "Writes should reset the TTL and a TTL of zero should never expire")
+	})
+
+	t.Run("Expired entries are treated as missing", func(t *testing.T) {
+		d, clock, log := newTTLDictionary(time.Minute)
+		d.Set("a", 1)
+		clock.Advance(time.Minute)
+
+		assert.Equal(t, 0, d.Count())
+		assert.Empty(t, d.ToMap())
+		assert.False(t, d.ContainsValue(1))
+
+		assert.True(t, d.TryAdd("a", 2), "TryAdd should replace an expired entry")
+		assert.False(t, d.TryUpdate("a", 3, 1))
+		assert.Equal(t, []string{"a=1 expired"}, log.Entries())
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		d, clock, log := newTTLDictionary(0)
+		for i := 0; i < 10; i++ {
+			d.SetWithTTL(fmt.Sprint(i), i, time.Duration(i+1)*time.Second)
+		}
+		clock.Advance(5 * time.Second)
+		assert.Equal(t, 5, d.DeleteExpired())
+		assert.Equal(t, 5, d.Count())
+		assert.Len(t, log.Entries(), 5)
+		assert.Equal(t, 0, d.DeleteExpired())
+	})
+
+	t.Run("Count skips expired entries without copying the shard", func(t *testing.T) {
+		d, clock, _ := newTTLDictionary(0)
+		for i := 0; i < 100; i++ {
+			d.SetWithTTL(fmt.Sprint(i), i, time.Duration(i%4+1)*time.Second)
+		}
+		data := Map(d.shards, func(shard *concurrentShard[string, int]) *shardData[string, int] { return shard.data })
+
+		clock.Advance(2 * time.Second)
+		assert.Equal(t, 50, d.Count())
+		assert.Len(t, d.Keys(), d.Count(), "Count should agree with the other reads")
+		assert.Equal(t, 50, d.DeleteExpired())
+		assert.Equal(t, 50, d.Count())
+		d.Set("0", 0)
+		d.Set("1", 1)
+		for i, shard := range d.shards {
+			assert.Same(t, data[i], shard.data, "Expected Count to leave shard %d unpinned", i)
+		}
+	})
+
+	t.Run("Eviction reasons", func(t *testing.T) {
+		d, _, log := newTTLDictionary(0)
+		d.Set("a", 1)
+		d.Set("a", 2)
+		d.Swap("a", 3)
+		d.Compute("a", func(v int, _ bool) (int, bool) { return v + 1, true })
+		d.Remove("a")
+		d.Remove("missing")
+		d.Set("b", 5)
+		d.RemoveWhere(func(string, int) bool { return true })
+		d.Set("c", 6)
+		d.Clear()
+
+		assert.Equal(t, []string{
+			"a=1 replaced",
+			"a=2 replaced",
+			"a=3 replaced",
+			"a=4 removed",
+			"b=5 removed",
+			"c=6 removed",
+		}, log.Entries())
+	})
+
+	t.Run("OnEvict may use the dictionary", func(t *testing.T) {
+		var d *ConcurrentDictionary[string, int]
+		d = NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, int]{
+			ShardCount: 1,
+			OnEvict: func(key string, value int, reason EvictionReason) {
+				if reason == EvictionRemoved {
+					d.Set("last-removed", value)
+				}
+			},
+		})
+		d.Set("a", 1)
+		d.Remove("a")
+		value, _ := d.Get("last-removed")
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("Snapshot excludes entries expired when it was taken", func(t *testing.T) {
+		d, clock, _ := newTTLDictionary(0)
+		d.SetWithTTL("short", 1, time.Second)
+		d.SetWithTTL("long", 2, time.Hour)
+		clock.Advance(time.Second)
+
+		snapshot := d.Snapshot()
+		clock.Advance(time.Hour)
+		assert.Equal(t, 1, snapshot.Count())
+		assert.Equal(t, map[string]int{"long": 2}, snapshot.ToMap())
+		assert.Equal(t, 0, d.Count())
+	})
+
+	t.Run("Janitor deletes expired entries", func(t *testing.T) {
+		clock := newFakeClock()
+		expired := make(chan string, 1)
+		d := NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[string, int]{
+			ShardCount:      1,
+			Clock:           clock.Now,
+			JanitorInterval: time.Millisecond,
+			OnEvict: func(key string, _ int, reason EvictionReason) {
+				if reason == EvictionExpired {
+					expired <- key
+				}
+			},
+		})
+		defer d.Close()
+
+		d.SetWithTTL("a", 1, time.Second)
+		clock.Advance(time.Second)
+		select {
+		case key := <-expired:
+			assert.Equal(t, "a", key)
+		case <-time.After(time.Second):
+			t.Fatal("The janitor should delete expired entries")
+		}
+		d.Close()
+		d.Close()
+	})
+
+	t.Run("EvictionReason String", func(t *testing.T) {
+		assert.Equal(t, "expired", EvictionExpired.String())
+		assert.Equal(t, "removed", EvictionRemoved.String())
+		assert.Equal(t, "replaced", EvictionReplaced.String())
+		assert.Equal(t, "unknown", EvictionReason(0).String())
+	})
 }
@@ -0,0 +1,205 @@
+package ectolinq
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrQueueClosed is returned when enqueuing to a closed queue, or dequeuing from a closed queue that has been drained
+var ErrQueueClosed = errors.New("queue is closed")
+
+// ConcurrentQueue is a thread-safe first-in, first-out queue for producer/consumer code.
+// Enqueue blocks while a bounded queue is full and Dequeue blocks while the queue is empty; both give up when their context is done.
+// Once closed, no more items can be added but the remaining items can still be dequeued.
+// The zero value is an open, unbounded queue
+type ConcurrentQueue[T any] struct {
+	mutex    sync.Mutex
+	items    ringBuffer[T]
+	capacity int
+	closed   bool
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+// NewConcurrentQueue creates a new unbounded concurrent queue. Enqueue never blocks
+func NewConcurrentQueue[T any]() *ConcurrentQueue[T] {
+	return &ConcurrentQueue[T]{}
+}
+
+// NewBoundedConcurrentQueue creates a new concurrent queue that holds at most capacity items.
+// A capacity less than one creates an unbounded queue
+// capacity: The maximum number of items in the queue
+func NewBoundedConcurrentQueue[T any](capacity int) *ConcurrentQueue[T] {
+	return &ConcurrentQueue[T]{
+		items:    newRingBuffer[T](capacity),
+		capacity: max(capacity, 0),
+	}
+}
+
+// Enqueue adds an item to the end of the queue, waiting for space if the queue is full.
+// It returns ErrQueueClosed if the queue is closed, or the context error if the context is done before there is space
+// ctx: The context to wait with
+// item: The item to add
+func (q *ConcurrentQueue[T]) Enqueue(ctx context.Context, item T) error {
+	for {
+		q.mutex.Lock()
+		if q.closed {
+			q.mutex.Unlock()
+			return ErrQueueClosed
+		}
+		if !q.full() {
+			q.items.pushBack(item)
+			broadcast(&q.notEmpty)
+			q.mutex.Unlock()
+			return nil
+		}
+		wait := waitChannel(&q.notFull)
+		q.mutex.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TryEnqueue adds an item to the end of the queue without waiting. It returns false if the queue is full or closed
+// item: The item to add
+func (q *ConcurrentQueue[T]) TryEnqueue(item T) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed || q.full() {
+		return false
+	}
+	q.items.pushBack(item)
+	broadcast(&q.notEmpty)
+	return true
+}
+
+// Dequeue removes and returns the item at the beginning of the queue, waiting for one if the queue is empty.
+// It returns ErrQueueClosed once the queue is closed and empty, or the context error if the context is done before an item arrives
+// ctx: The context to wait with
+func (q *ConcurrentQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		q.mutex.Lock()
+		if item, ok := q.items.popFront(); ok {
+			broadcast(&q.notFull)
+			q.mutex.Unlock()
+			return item, nil
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			var zero T
+			return zero, ErrQueueClosed
+		}
+		wait := waitChannel(&q.notEmpty)
+		q.mutex.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// TryDequeue removes and returns the item at the beginning of the queue without waiting, or false if the queue is empty
+func (q *ConcurrentQueue[T]) TryDequeue() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, ok := q.items.popFront()
+	if ok {
+		broadcast(&q.notFull)
+	}
+	return item, ok
+}
+
+// TryPeek returns the item at the beginning of the queue without removing it, or false if the queue is empty
+func (q *ConcurrentQueue[T]) TryPeek() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.peekFront()
+}
+
+// Close stops the queue from accepting new items and wakes every waiting caller.
+// Items already in the queue can still be dequeued. Closing a closed queue does nothing
+func (q *ConcurrentQueue[T]) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	broadcast(&q.notEmpty)
+	broadcast(&q.notFull)
+}
+
+// IsClosed returns if the queue has been closed
+func (q *ConcurrentQueue[T]) IsClosed() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.closed
+}
+
+// Seq returns an iterator that dequeues items until the queue is closed and drained, or the context is done.
+// Each item is yielded to exactly one consumer, so several goroutines can range over the same queue
+// ctx: The context to wait with
+func (q *ConcurrentQueue[T]) Seq(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, err := q.Dequeue(ctx)
+			if err != nil || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the number of items in the queue
+func (q *ConcurrentQueue[T]) Count() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.len()
+}
+
+// Capacity returns the maximum number of items in the queue, or 0 if it is unbounded
+func (q *ConcurrentQueue[T]) Capacity() int {
+	return q.capacity
+}
+
+// ToArray returns a snapshot of the items in the queue as an array, from front to back
+func (q *ConcurrentQueue[T]) ToArray() []T {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.toSlice()
+}
+
+// ToList returns a snapshot of the items in the queue as a list, from front to back
+func (q *ConcurrentQueue[T]) ToList() List[T] {
+	return q.ToArray()
+}
+
+func (q *ConcurrentQueue[T]) full() bool {
+	return q.capacity > 0 && q.items.len() >= q.capacity
+}
+
+// waitChannel returns the channel to wait on for the next broadcast, creating it if nobody is waiting yet.
+// The caller must hold the lock
+func waitChannel(ch *chan struct{}) chan struct{} {
+	if *ch == nil {
+		*ch = make(chan struct{})
+	}
+	return *ch
+}
+
+// broadcast wakes everyone waiting on the channel. The caller must hold the lock
+func broadcast(ch *chan struct{}) {
+	if *ch != nil {
+		close(*ch)
+		*ch = nil
+	}
+}
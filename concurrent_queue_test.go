@@ -0,0 +1,245 @@
+package ectolinq
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrentQueue(t *testing.T) {
+	t.Run("Create unbounded queue", func(t *testing.T) {
+		q := NewConcurrentQueue[int]()
+		require.NotNil(t, q)
+		assert.Equal(t, 0, q.Count())
+		assert.Equal(t, 0, q.Capacity())
+		for i := 0; i < 100; i++ {
+			assert.True(t, q.TryEnqueue(i), "An unbounded queue should never be full")
+		}
+	})
+
+	t.Run("Create bounded queue", func(t *testing.T) {
+		q := NewBoundedConcurrentQueue[int](2)
+		assert.Equal(t, 2, q.Capacity())
+		assert.True(t, q.TryEnqueue(1))
+		assert.True(t, q.TryEnqueue(2))
+		assert.False(t, q.TryEnqueue(3), "TryEnqueue should fail when the queue is full")
+	})
+
+	t.Run("Zero value is usable", func(t *testing.T) {
+		var q ConcurrentQueue[int]
+		require.NoError(t, q.Enqueue(context.Background(), 1))
+		item, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, item)
+	})
+}
+
+func TestConcurrentQueueFIFO(t *testing.T) {
+	q := NewConcurrentQueue[int]()
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, q.Enqueue(ctx, i))
+	}
+	assert.Equal(t, []int{1, 2, 3}, q.ToArray())
+
+	peeked, ok := q.TryPeek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, peeked)
+
+	item, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, item)
+	item, ok = q.TryDequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	assert.Equal(t, List[int]{3}, q.ToList())
+
+	q.TryDequeue()
+	_, ok = q.TryDequeue()
+	assert.False(t, ok, "TryDequeue should return false for an empty queue")
+}
+
+func TestConcurrentQueueBlocking(t *testing.T) {
+	t.Run("Dequeue waits for an item", func(t *testing.T) {
+		q := NewConcurrentQueue[int]()
+		result := make(chan int)
+		go func() {
+			item, _ := q.Dequeue(context.Background())
+			result <- item
+		}()
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, q.Enqueue(context.Background(), 42))
+		assert.Equal(t, 42, <-result)
+	})
+
+	t.Run("Enqueue waits for space", func(t *testing.T) {
+		q := NewBoundedConcurrentQueue[int](1)
+		require.NoError(t, q.Enqueue(context.Background(), 1))
+		done := make(chan error)
+		go func() {
+			done <- q.Enqueue(context.Background(), 2)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("Enqueue should block while the queue is full")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		item, _ := q.TryDequeue()
+		assert.Equal(t, 1, item)
+		require.NoError(t, <-done)
+		assert.Equal(t, []int{2}, q.ToArray())
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		q := NewBoundedConcurrentQueue[int](1)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := NewConcurrentQueue[int]().Dequeue(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		q.TryEnqueue(1)
+		err = q.Enqueue(ctx, 2)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, q.Count(), "A cancelled Enqueue should not add the item")
+	})
+}
+
+func TestConcurrentQueueClose(t *testing.T) {
+	t.Run("Close drains remaining items", func(t *testing.T) {
+		q := NewConcurrentQueue[int]()
+		q.TryEnqueue(1)
+		q.TryEnqueue(2)
+		q.Close()
+		q.Close()
+		assert.True(t, q.IsClosed())
+
+		assert.ErrorIs(t, q.Enqueue(context.Background(), 3), ErrQueueClosed)
+		assert.False(t, q.TryEnqueue(3))
+
+		item, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, item)
+		item, err = q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, item)
+		_, err = q.Dequeue(context.Background())
+		assert.ErrorIs(t, err, ErrQueueClosed)
+	})
+
+	t.Run("Close wakes waiting callers", func(t *testing.T) {
+		empty := NewConcurrentQueue[int]()
+		full := NewBoundedConcurrentQueue[int](1)
+		full.TryEnqueue(1)
+
+		errs := make(chan error, 2)
+		go func() {
+			_, err := empty.Dequeue(context.Background())
+			errs <- err
+		}()
+		go func() {
+			errs <- full.Enqueue(context.Background(), 2)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		empty.Close()
+		full.Close()
+		assert.ErrorIs(t, <-errs, ErrQueueClosed)
+		assert.ErrorIs(t, <-errs, ErrQueueClosed)
+	})
+
+	t.Run("Seq ends on close", func(t *testing.T) {
+		q := NewConcurrentQueue[int]()
+		go func() {
+			for i := 1; i <= 3; i++ {
+				q.Enqueue(context.Background(), i)
+			}
+			q.Close()
+		}()
+
+		var items []int
+		for item := range q.Seq(context.Background()) {
+			items = append(items, item)
+		}
+		assert.Equal(t, []int{1, 2, 3}, items)
+	})
+
+	t.Run("Seq ends when the context is done", func(t *testing.T) {
+		q := NewConcurrentQueue[int]()
+		q.TryEnqueue(1)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var items []int
+		for item := range q.Seq(ctx) {
+			items = append(items, item)
+		}
+		assert.Equal(t, []int{1}, items)
+	})
+}
+
+func TestConcurrentQueueFanInFanOut(t *testing.T) {
+	const producers = 8
+	const consumers = 8
+	const perProducer = 2000
+
+	q := NewBoundedConcurrentQueue[int](16)
+	ctx := context.Background()
+
+	var producersDone sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		producersDone.Add(1)
+		go func(p int) {
+			defer producersDone.Done()
+			for i := 0; i < perProducer; i++ {
+				if !q.TryEnqueue(p*perProducer + i) {
+					assert.NoError(t, q.Enqueue(ctx, p*perProducer+i))
+				}
+			}
+		}(p)
+	}
+
+	results := make([][]int, consumers)
+	var consumersDone sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		consumersDone.Add(1)
+		go func(c int) {
+			defer consumersDone.Done()
+			if c%2 == 0 {
+				for item := range q.Seq(ctx) {
+					results[c] = append(results[c], item)
+				}
+				return
+			}
+			for {
+				item, err := q.Dequeue(ctx)
+				if err != nil {
+					assert.ErrorIs(t, err, ErrQueueClosed)
+					return
+				}
+				results[c] = append(results[c], item)
+			}
+		}(c)
+	}
+
+	producersDone.Wait()
+	q.Close()
+	consumersDone.Wait()
+
+	var all []int
+	for _, items := range results {
+		all = append(all, items...)
+	}
+	slices.Sort(all)
+	require.Len(t, all, producers*perProducer, "Every item should be dequeued exactly once")
+	for i, item := range all {
+		assert.Equal(t, i, item)
+	}
+	assert.Equal(t, 0, q.Count())
+}
@@ -0,0 +1,300 @@
+package ectolinq
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+	"weak"
+)
+
+// shardBatchSize is the most entries a shard iterator copies under one hold of the read lock
+const shardBatchSize = 64
+
+// concurrentShard is one independently locked part of a ConcurrentDictionary.
+// Iterators read it in batches of shardBatchSize under the read lock, so writers never wait for more than one batch.
+// Snapshots are versioned: a snapshot only records the shard's newest version, and each writer saves the entry it changes
+// into that version the first time, so neither taking a snapshot nor writing afterwards copies the shard
+type concurrentShard[K comparable, V any] struct {
+	data  *shardData[K, V]
+	mutex sync.RWMutex
+	// next is the number of the last added entry. It keeps counting across reset, so numbers are never reused
+	next uint64
+	// version is the newest version taken by a snapshot. It is weak so the version is collected once no snapshot uses it,
+	// and writers then stop saving entries
+	version weak.Pointer[shardVersion[K, V]]
+}
+
+// shardData holds the entries of a shard.
+// Entries are kept in the order they were added, numbered in increasing order, so an iterator can resume after the last entry it saw.
+// Removed entries leave a hole until more than half of the entries are holes, and then the entries are compacted
+type shardData[K comparable, V any] struct {
+	// index maps each key to its position in entries
+	index   map[K]int
+	entries []shardEntry[K, V]
+	// expires holds the expiry time of the keys that have one. Every key in it is also in index
+	expires map[K]time.Time
+}
+
+type shardEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	seq     uint64
+	removed bool
+}
+
+// shardVersion is the state of a shard when a snapshot was taken, kept as the entries that have changed since.
+// An entry changed after several versions were taken is saved in the newest one only, so a version also reads the versions taken after it
+type shardVersion[K comparable, V any] struct {
+	// until is the number of the last entry added before the version was taken. Entries numbered after it are not part of it
+	until uint64
+	// saved holds each entry as it was before its first change while this was the newest version
+	saved map[K]savedEntry[V]
+	// next is the version taken after this one, or nil if this is the newest
+	next *shardVersion[K, V]
+}
+
+// savedEntry is an entry as a version saw it. A zero expiry never expires
+type savedEntry[V any] struct {
+	value  V
+	seq    uint64
+	expiry time.Time
+}
+
+// expired returns if the entry has an expiry time that is not after now
+func (e savedEntry[V]) expired(now time.Time) bool {
+	return !e.expiry.IsZero() && !now.Before(e.expiry)
+}
+
+// eviction is an entry that left a dictionary or cache, held until OnEvict can be called outside the lock
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+func newShardData[K comparable, V any]() *shardData[K, V] {
+	return &shardData[K, V]{index: make(map[K]int)}
+}
+
+// lookup returns the value for the key, whether or not it has expired
+func (s *shardData[K, V]) lookup(key K) (V, bool) {
+	i, ok := s.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return s.entries[i].value, true
+}
+
+// expired returns if the key has an expiry time that is not after now
+func (s *shardData[K, V]) expired(key K, now time.Time) bool {
+	expiry, ok := s.expires[key]
+	return ok && !now.Before(expiry)
+}
+
+// compact drops the holes left by removed entries once they outnumber the live entries.
+// Entries keep their order and numbers, so iterators resume in the right place
+func (s *shardData[K, V]) compact() {
+	if holes := len(s.entries) - len(s.index); holes < shardBatchSize || holes <= len(s.index) {
+		return
+	}
+	entries := make([]shardEntry[K, V], 0, len(s.index))
+	for _, entry := range s.entries {
+		if !entry.removed {
+			s.index[entry.key] = len(entries)
+			entries = append(entries, entry)
+		}
+	}
+	s.entries = entries
+}
+
+// count returns the number of entries that have not expired at now, under the read lock.
+// Only the keys in expires can have expired, so a shard without any time to live is counted without a scan
+func (s *concurrentShard[K, V]) count(now time.Time) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	count := len(s.data.index)
+	for _, expiry := range s.data.expires {
+		if !now.Before(expiry) {
+			count--
+		}
+	}
+	return count
+}
+
+// all yields the entries that have not expired at now without holding the lock while yield runs.
+// The entries are copied in batches under the read lock, so stopping early skips the rest of the shard.
+// Entries added after iteration starts are not yielded, so a key that is removed and added again is not seen twice.
+// It returns false once yield does
+func (s *concurrentShard[K, V]) all(now time.Time, yield func(K, V) bool) bool {
+	s.mutex.RLock()
+	until := s.next
+	s.mutex.RUnlock()
+	return s.iterate(until, nil, now, yield)
+}
+
+// allAt yields the entries that were present when the version was taken and had not expired at now, in batches like all.
+// It returns false once yield does
+func (s *concurrentShard[K, V]) allAt(version *shardVersion[K, V], now time.Time, yield func(K, V) bool) bool {
+	return s.iterate(version.until, version, now, yield)
+}
+
+// iterate yields the entries numbered up to until, as they were when the version was taken or as they are now if it is nil
+func (s *concurrentShard[K, V]) iterate(until uint64, version *shardVersion[K, V], now time.Time, yield func(K, V) bool) bool {
+	batch := make([]MapEntry[K, V], 0, shardBatchSize)
+	var after uint64
+	for done := false; !done; {
+		batch, after, done = s.batch(batch[:0], after, until, version, now)
+		for _, entry := range batch {
+			if !yield(entry.Key, entry.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// batch appends to buf up to shardBatchSize entries numbered after after and up to until, reading them under the read lock.
+// With a version, an entry is added as the version saw it, and holes left since the version was taken still count.
+// It returns the entries, the number of the last entry it looked at and whether there are no more entries up to until
+func (s *concurrentShard[K, V]) batch(buf []MapEntry[K, V], after uint64, until uint64, version *shardVersion[K, V], now time.Time) ([]MapEntry[K, V], uint64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	entries := s.data.entries
+	i, _ := slices.BinarySearchFunc(entries, after+1, func(entry shardEntry[K, V], seq uint64) int {
+		return cmp.Compare(entry.seq, seq)
+	})
+	// Holes are skipped without counting towards the batch, so bound how many entries one batch looks at
+	for end := min(i+4*shardBatchSize, len(entries)); i < end && len(buf) < shardBatchSize; i++ {
+		entry := &entries[i]
+		if entry.seq > until {
+			return buf, after, true
+		}
+		after = entry.seq
+		if version != nil {
+			// The key may have been removed and added again since, so only the entry with the number the version saw is yielded
+			if saved, ok := s.lookupAt(version, entry.key); ok && saved.seq == entry.seq && !saved.expired(now) {
+				buf = append(buf, MapEntry[K, V]{Key: entry.key, Value: saved.value})
+			}
+		} else if !entry.removed && !s.data.expired(entry.key, now) {
+			buf = append(buf, MapEntry[K, V]{Key: entry.key, Value: entry.value})
+		}
+	}
+	return buf, after, i >= len(entries) || entries[i].seq > until
+}
+
+// getAt returns the value for the key when the version was taken, or false if it was missing or had expired at now, under the read lock
+func (s *concurrentShard[K, V]) getAt(version *shardVersion[K, V], key K, now time.Time) (V, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	saved, ok := s.lookupAt(version, key)
+	if !ok || saved.expired(now) {
+		var zero V
+		return zero, false
+	}
+	return saved.value, true
+}
+
+// lookupAt returns the entry for the key as it was when the version was taken, whether or not it has expired.
+// The first version from it onwards that saved the key holds the entry as it was; otherwise it has not changed since. The caller must hold the lock
+func (s *concurrentShard[K, V]) lookupAt(version *shardVersion[K, V], key K) (savedEntry[V], bool) {
+	for v := version; v != nil; v = v.next {
+		if saved, ok := v.saved[key]; ok {
+			return saved, saved.seq <= version.until
+		}
+	}
+	i, ok := s.data.index[key]
+	if !ok || s.data.entries[i].seq > version.until {
+		return savedEntry[V]{}, false
+	}
+	return savedEntry[V]{value: s.data.entries[i].value, seq: s.data.entries[i].seq, expiry: s.data.expires[key]}, true
+}
+
+// snapshot returns the shard's newest version, taking a new one if an entry was added or saved since. It copies no entries.
+// The caller must hold the write lock
+func (s *concurrentShard[K, V]) snapshot() *shardVersion[K, V] {
+	current := s.version.Value()
+	if current != nil && current.until == s.next && len(current.saved) == 0 {
+		return current
+	}
+	version := &shardVersion[K, V]{until: s.next, saved: make(map[K]savedEntry[V])}
+	if current != nil {
+		current.next = version
+	}
+	s.version = weak.Make(version)
+	return version
+}
+
+// save keeps the entry for the key in the newest version before it is first changed, if a snapshot may still read it.
+// It returns if any snapshot may still read the shard. The caller must hold the write lock
+func (s *concurrentShard[K, V]) save(key K) bool {
+	version := s.version.Value()
+	if version == nil {
+		return false
+	}
+	i, ok := s.data.index[key]
+	if !ok || s.data.entries[i].seq > version.until {
+		return true
+	}
+	if _, ok := version.saved[key]; !ok {
+		version.saved[key] = savedEntry[V]{value: s.data.entries[i].value, seq: s.data.entries[i].seq, expiry: s.data.expires[key]}
+	}
+	return true
+}
+
+// reset replaces the data with an empty set of entries. The caller must hold the write lock.
+// While a snapshot may still read the shard, every entry is removed one by one instead so the snapshot can find them
+func (s *concurrentShard[K, V]) reset() {
+	if s.version.Value() == nil {
+		s.data = newShardData[K, V]()
+		return
+	}
+	for key := range s.data.index {
+		s.delete(key)
+	}
+}
+
+// delete removes the key and its expiry time. The caller must hold the write lock.
+// While a snapshot may still read the shard, the hole keeps its key and is not compacted, so the snapshot's iterators find the saved entry
+func (s *concurrentShard[K, V]) delete(key K) {
+	data := s.data
+	i, ok := data.index[key]
+	if !ok {
+		return
+	}
+	read := s.save(key)
+	if read {
+		data.entries[i] = shardEntry[K, V]{key: key, seq: data.entries[i].seq, removed: true}
+	} else {
+		// Clear the hole so it does not keep the key and value alive
+		data.entries[i] = shardEntry[K, V]{seq: data.entries[i].seq, removed: true}
+	}
+	delete(data.index, key)
+	delete(data.expires, key)
+	if !read {
+		data.compact()
+	}
+}
+
+// put sets the value for the key, with an expiry time unless expiry is zero. The caller must hold the write lock.
+// A key that is already present keeps its place, and a new key is added after every other entry
+func (s *concurrentShard[K, V]) put(key K, value V, expiry time.Time) {
+	s.save(key)
+	data := s.data
+	if i, ok := data.index[key]; ok {
+		data.entries[i].value = value
+	} else {
+		s.next++
+		data.index[key] = len(data.entries)
+		data.entries = append(data.entries, shardEntry[K, V]{key: key, value: value, seq: s.next})
+	}
+	if expiry.IsZero() {
+		delete(data.expires, key)
+		return
+	}
+	if data.expires == nil {
+		data.expires = make(map[K]time.Time)
+	}
+	data.expires[key] = expiry
+}
@@ -0,0 +1,181 @@
+package ectolinq
+
+import "sync/atomic"
+
+// ConcurrentStack is a thread-safe last-in, first-out stack.
+// It is a lock-free Treiber stack: every operation swaps the top of the stack with a single compare-and-swap, so no goroutine ever blocks another.
+// The zero value is an empty stack ready to use
+type ConcurrentStack[T any] struct {
+	head atomic.Pointer[stackNode[T]]
+}
+
+// stackNode is an immutable link in the stack. depth is the number of nodes from this one to the bottom, so Count never has to walk the stack
+type stackNode[T any] struct {
+	value T
+	next  *stackNode[T]
+	depth int
+}
+
+// NewConcurrentStack creates a new concurrent stack
+func NewConcurrentStack[T any]() *ConcurrentStack[T] {
+	return &ConcurrentStack[T]{}
+}
+
+// ToConcurrentStack creates a new concurrent stack from an array. The last element of the array is on the top of the stack
+// items: The array to create the stack from
+func ToConcurrentStack[T any](items []T) *ConcurrentStack[T] {
+	s := NewConcurrentStack[T]()
+	s.PushRange(items...)
+	return s
+}
+
+// Push pushes an item onto the stack
+// item: The item to push
+func (s *ConcurrentStack[T]) Push(item T) {
+	node := &stackNode[T]{value: item}
+	for {
+		head := s.head.Load()
+		node.next = head
+		node.depth = head.getDepth() + 1
+		if s.head.CompareAndSwap(head, node) {
+			return
+		}
+	}
+}
+
+// PushRange pushes the items onto the stack as a single atomic operation. The last item ends up on the top of the stack
+// items: The items to push
+func (s *ConcurrentStack[T]) PushRange(items ...T) {
+	if len(items) == 0 {
+		return
+	}
+
+	// Each node is allocated on its own, as in Push, so a popped node can be collected while the rest of the batch is still on the stack
+	nodes := make([]*stackNode[T], len(items))
+	for i, item := range items {
+		nodes[i] = &stackNode[T]{value: item}
+		if i > 0 {
+			nodes[i].next = nodes[i-1]
+		}
+	}
+	top := nodes[len(nodes)-1]
+
+	for {
+		head := s.head.Load()
+		nodes[0].next = head
+		for i, node := range nodes {
+			node.depth = head.getDepth() + i + 1
+		}
+		if s.head.CompareAndSwap(head, top) {
+			return
+		}
+	}
+}
+
+// TryPop removes and returns the item on the top of the stack, or false if the stack is empty
+func (s *ConcurrentStack[T]) TryPop() (T, bool) {
+	for {
+		head := s.head.Load()
+		if head == nil {
+			var zero T
+			return zero, false
+		}
+		if s.head.CompareAndSwap(head, head.next) {
+			return head.value, true
+		}
+	}
+}
+
+// PopRange removes up to count items from the top of the stack as a single atomic operation.
+// The items are returned in the order they were popped, so the former top of the stack comes first
+// count: The maximum number of items to pop
+func (s *ConcurrentStack[T]) PopRange(count int) []T {
+	if count <= 0 {
+		return []T{}
+	}
+	for {
+		head := s.head.Load()
+		bottom := head
+		for i := 1; i < count && bottom != nil; i++ {
+			bottom = bottom.next
+		}
+		var rest *stackNode[T]
+		if bottom != nil {
+			rest = bottom.next
+		}
+		if !s.head.CompareAndSwap(head, rest) {
+			continue
+		}
+
+		items := make([]T, 0, head.getDepth()-rest.getDepth())
+		for node := head; node != rest; node = node.next {
+			items = append(items, node.value)
+		}
+		return items
+	}
+}
+
+// TryPeek returns the item on the top of the stack without removing it, or false if the stack is empty
+func (s *ConcurrentStack[T]) TryPeek() (T, bool) {
+	if head := s.head.Load(); head != nil {
+		return head.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Count returns the number of items in the stack
+func (s *ConcurrentStack[T]) Count() int {
+	return s.head.Load().getDepth()
+}
+
+// IsEmpty returns if the stack has no items
+func (s *ConcurrentStack[T]) IsEmpty() bool {
+	return s.head.Load() == nil
+}
+
+// Clear removes all items from the stack
+func (s *ConcurrentStack[T]) Clear() {
+	s.head.Store(nil)
+}
+
+// Contains returns if the stack contains the given item
+// item: The item to check for
+func (s *ConcurrentStack[T]) Contains(item T) bool {
+	return s.ContainsWhere(func(other T) bool {
+		return Equals(other, item)
+	})
+}
+
+// ContainsWhere returns if the stack contains an item that satisfies the given predicate
+// fn: The predicate to check for
+func (s *ConcurrentStack[T]) ContainsWhere(fn func(T) bool) bool {
+	for node := s.head.Load(); node != nil; node = node.next {
+		if fn(node.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToArray returns a snapshot of the items in the stack as an array. Like Stack, the top of the stack is the last element
+func (s *ConcurrentStack[T]) ToArray() []T {
+	head := s.head.Load()
+	items := make([]T, head.getDepth())
+	for node := head; node != nil; node = node.next {
+		items[node.depth-1] = node.value
+	}
+	return items
+}
+
+// ToList returns a snapshot of the items in the stack as a list. Like Stack, the top of the stack is the last element
+func (s *ConcurrentStack[T]) ToList() List[T] {
+	return s.ToArray()
+}
+
+func (n *stackNode[T]) getDepth() int {
+	if n == nil {
+		return 0
+	}
+	return n.depth
+}
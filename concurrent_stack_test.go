@@ -0,0 +1,176 @@
+package ectolinq
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrentStack(t *testing.T) {
+	t.Run("Create new stack", func(t *testing.T) {
+		s := NewConcurrentStack[int]()
+		require.NotNil(t, s)
+		assert.Equal(t, 0, s.Count())
+		assert.True(t, s.IsEmpty())
+		_, ok := s.TryPop()
+		assert.False(t, ok, "TryPop should return false for an empty stack")
+		_, ok = s.TryPeek()
+		assert.False(t, ok, "TryPeek should return false for an empty stack")
+	})
+
+	t.Run("Create stack from slice", func(t *testing.T) {
+		s := ToConcurrentStack([]int{1, 2, 3})
+		assert.Equal(t, 3, s.Count())
+		assert.Equal(t, []int{1, 2, 3}, s.ToArray())
+		top, _ := s.TryPeek()
+		assert.Equal(t, 3, top, "The last element should be on top")
+	})
+}
+
+func TestConcurrentStackPushPop(t *testing.T) {
+	s := NewConcurrentStack[int]()
+	s.Push(1)
+	s.Push(2)
+	assert.Equal(t, 2, s.Count())
+
+	item, ok := s.TryPop()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	item, _ = s.TryPop()
+	assert.Equal(t, 1, item)
+	assert.Equal(t, 0, s.Count())
+}
+
+func TestConcurrentStackRanges(t *testing.T) {
+	t.Run("PushRange puts the last item on top", func(t *testing.T) {
+		s := ToConcurrentStack([]int{1})
+		s.PushRange(2, 3, 4)
+		s.PushRange()
+		assert.Equal(t, 4, s.Count())
+		assert.Equal(t, List[int]{1, 2, 3, 4}, s.ToList())
+	})
+
+	t.Run("PopRange returns the top first", func(t *testing.T) {
+		s := ToConcurrentStack([]int{1, 2, 3, 4})
+		assert.Equal(t, []int{4, 3}, s.PopRange(2))
+		assert.Equal(t, 2, s.Count())
+		assert.Equal(t, []int{2, 1}, s.PopRange(5), "PopRange should stop at the bottom of the stack")
+		assert.Empty(t, s.PopRange(1))
+		assert.Empty(t, s.PopRange(0))
+	})
+
+	t.Run("Popped items of a range can be collected", func(t *testing.T) {
+		var collected atomic.Int64
+		items := make([]*[1024]byte, 10)
+		for i := range items {
+			items[i] = new([1024]byte)
+			runtime.SetFinalizer(items[i], func(*[1024]byte) { collected.Add(1) })
+		}
+		s := NewConcurrentStack[*[1024]byte]()
+		s.PushRange(items...)
+		items = nil
+		assert.Len(t, s.PopRange(9), 9)
+
+		for i := 0; i < 20 && collected.Load() < 9; i++ {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, int64(9), collected.Load(), "The popped items should not be kept alive by the item left on the stack")
+		assert.Equal(t, 1, s.Count())
+		runtime.KeepAlive(s)
+	})
+}
+
+func TestConcurrentStackContains(t *testing.T) {
+	s := ToConcurrentStack([]int{1, 2, 3})
+	assert.True(t, s.Contains(2))
+	assert.False(t, s.Contains(4))
+	assert.True(t, s.ContainsWhere(func(n int) bool { return n > 2 }))
+	s.Clear()
+	assert.True(t, s.IsEmpty())
+	assert.False(t, s.Contains(2))
+}
+
+func TestConcurrentStackContention(t *testing.T) {
+	const goroutines = 8
+	const perGoroutine = 2000
+
+	s := NewConcurrentStack[int]()
+	popped := make([][]int, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i += 2 {
+				base := g*perGoroutine + i
+				if i%4 == 0 {
+					s.Push(base)
+					s.Push(base + 1)
+				} else {
+					s.PushRange(base, base+1)
+				}
+				if item, ok := s.TryPop(); ok {
+					popped[g] = append(popped[g], item)
+				}
+				popped[g] = append(popped[g], s.PopRange(1)...)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	all := s.PopRange(s.Count() + 1)
+	for _, items := range popped {
+		all = append(all, items...)
+	}
+	slices.Sort(all)
+	require.Len(t, all, goroutines*perGoroutine, "Every item should be popped exactly once")
+	for i, item := range all {
+		assert.Equal(t, i, item)
+	}
+}
+
+// mutexStack is the baseline the benchmarks compare against: a Stack guarded by a mutex
+type mutexStack[T any] struct {
+	mutex sync.Mutex
+	stack *Stack[T]
+}
+
+func (s *mutexStack[T]) Push(item T) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stack.Push(item)
+}
+
+func (s *mutexStack[T]) TryPop() (T, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	item, err := s.stack.Pop()
+	return item, err == nil
+}
+
+func BenchmarkConcurrentStack(b *testing.B) {
+	s := NewConcurrentStack[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			s.Push(i)
+			s.TryPop()
+		}
+	})
+}
+
+func BenchmarkMutexStack(b *testing.B) {
+	s := &mutexStack[int]{stack: NewStack[int]()}
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			s.Push(i)
+			s.TryPop()
+		}
+	})
+}
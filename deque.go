@@ -0,0 +1,158 @@
+package ectolinq
+
+import "iter"
+
+// Deque is a double-ended queue backed by a growable ring buffer.
+// Pushing and popping at either end is amortized O(1) and indexed access is O(1)
+type Deque[T any] struct {
+	items ringBuffer[T]
+}
+
+// NewDeque creates a new deque
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+// NewDequeWithCapacity creates a new deque that can hold capacity items before it needs to grow
+// capacity: The number of items to allocate space for
+func NewDequeWithCapacity[T any](capacity int) *Deque[T] {
+	return &Deque[T]{
+		items: newRingBuffer[T](capacity),
+	}
+}
+
+// ToDeque creates a new deque from an array. The first element of the array is at the front of the deque
+// items: The array to create the deque from
+func ToDeque[T any](items []T) *Deque[T] {
+	return &Deque[T]{
+		items: ringBufferFrom(items),
+	}
+}
+
+// PushFront adds an item to the front of the deque
+// item: The item to add
+func (d *Deque[T]) PushFront(item T) {
+	d.items.pushFront(item)
+}
+
+// PushBack adds an item to the back of the deque
+// item: The item to add
+func (d *Deque[T]) PushBack(item T) {
+	d.items.pushBack(item)
+}
+
+// PopFront removes and returns the item at the front of the deque, or false if the deque is empty
+func (d *Deque[T]) PopFront() (T, bool) {
+	return d.items.popFront()
+}
+
+// PopBack removes and returns the item at the back of the deque, or false if the deque is empty
+func (d *Deque[T]) PopBack() (T, bool) {
+	return d.items.popBack()
+}
+
+// PeekFront returns the item at the front of the deque without removing it, or false if the deque is empty
+func (d *Deque[T]) PeekFront() (T, bool) {
+	return d.items.peekFront()
+}
+
+// PeekBack returns the item at the back of the deque without removing it, or false if the deque is empty
+func (d *Deque[T]) PeekBack() (T, bool) {
+	return d.items.peekBack()
+}
+
+// At returns the item at the provided index counted from the front, or the zero value if the index is out of bounds
+// index: The index
+func (d *Deque[T]) At(index int) T {
+	if index < 0 || index >= d.items.len() {
+		var zero T
+		return zero
+	}
+	return d.items.at(index)
+}
+
+// Rotate moves the last n items to the front of the deque. A negative n moves the first -n items to the back
+// n: The number of steps to rotate
+func (d *Deque[T]) Rotate(n int) {
+	count := d.items.len()
+	if count < 2 {
+		return
+	}
+	n %= count
+	if n < 0 {
+		n += count
+	}
+	// Rotate whichever way moves fewer items
+	if n <= count/2 {
+		for i := 0; i < n; i++ {
+			item, _ := d.items.popBack()
+			d.items.pushFront(item)
+		}
+		return
+	}
+	for i := 0; i < count-n; i++ {
+		item, _ := d.items.popFront()
+		d.items.pushBack(item)
+	}
+}
+
+// Count returns the number of items in the deque
+func (d *Deque[T]) Count() int {
+	return d.items.len()
+}
+
+// Clear removes all items from the deque. The capacity is kept
+func (d *Deque[T]) Clear() {
+	d.items.clear()
+}
+
+// Contains returns if the deque contains the given item
+// item: The item to check for
+func (d *Deque[T]) Contains(item T) bool {
+	return d.ContainsWhere(func(other T) bool {
+		return Equals(other, item)
+	})
+}
+
+// ContainsWhere returns if the deque contains an item that satisfies the given predicate
+// fn: The predicate to check for
+func (d *Deque[T]) ContainsWhere(fn func(T) bool) bool {
+	for _, item := range d.All() {
+		if fn(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns an iterator over the indexes and items from front to back
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.items.len(); i++ {
+			if !yield(i, d.items.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indexes and items from back to front
+func (d *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := d.items.len() - 1; i >= 0; i-- {
+			if !yield(i, d.items.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// ToArray returns the items in the deque as an array, from front to back
+func (d *Deque[T]) ToArray() []T {
+	return d.items.toSlice()
+}
+
+// ToList returns the items in the deque as a list, from front to back
+func (d *Deque[T]) ToList() List[T] {
+	return d.ToArray()
+}
@@ -0,0 +1,149 @@
+package ectolinq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeque(t *testing.T) {
+	t.Run("Create new deque", func(t *testing.T) {
+		d := NewDeque[int]()
+		assert.NotNil(t, d)
+		assert.Equal(t, 0, d.Count())
+	})
+
+	t.Run("Create deque from slice", func(t *testing.T) {
+		d := ToDeque([]int{1, 2, 3})
+		assert.Equal(t, []int{1, 2, 3}, d.ToArray())
+		assert.Equal(t, 0, NewDequeWithCapacity[int](8).Count())
+	})
+}
+
+func TestDequePushPop(t *testing.T) {
+	t.Run("Push and pop at both ends", func(t *testing.T) {
+		d := NewDeque[int]()
+		d.PushBack(2)
+		d.PushFront(1)
+		d.PushBack(3)
+		d.PushFront(0)
+		assert.Equal(t, []int{0, 1, 2, 3}, d.ToArray())
+
+		item, ok := d.PopFront()
+		assert.True(t, ok)
+		assert.Equal(t, 0, item)
+		item, ok = d.PopBack()
+		assert.True(t, ok)
+		assert.Equal(t, 3, item)
+		assert.Equal(t, []int{1, 2}, d.ToArray())
+	})
+
+	t.Run("Pop from empty deque", func(t *testing.T) {
+		d := NewDeque[int]()
+		_, ok := d.PopFront()
+		assert.False(t, ok)
+		_, ok = d.PopBack()
+		assert.False(t, ok)
+	})
+
+	t.Run("Used as a stack and a queue across growth", func(t *testing.T) {
+		d := NewDequeWithCapacity[int](2)
+		for i := 0; i < 100; i++ {
+			d.PushFront(i)
+		}
+		for i := 0; i < 100; i++ {
+			item, _ := d.PopBack()
+			assert.Equal(t, i, item, "PushFront then PopBack should be FIFO")
+		}
+		for i := 0; i < 100; i++ {
+			d.PushFront(i)
+		}
+		for i := 99; i >= 0; i-- {
+			item, _ := d.PopFront()
+			assert.Equal(t, i, item, "PushFront then PopFront should be LIFO")
+		}
+	})
+}
+
+func TestDequePeek(t *testing.T) {
+	d := ToDeque([]int{1, 2, 3})
+	front, ok := d.PeekFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, front)
+	back, ok := d.PeekBack()
+	assert.True(t, ok)
+	assert.Equal(t, 3, back)
+	assert.Equal(t, 3, d.Count(), "Peek should not remove items")
+
+	_, ok = NewDeque[int]().PeekBack()
+	assert.False(t, ok)
+}
+
+func TestDequeAt(t *testing.T) {
+	d := NewDeque[string]()
+	d.PushBack("b")
+	d.PushFront("a")
+	d.PushBack("c")
+	assert.Equal(t, "a", d.At(0))
+	assert.Equal(t, "c", d.At(2))
+	assert.Equal(t, "", d.At(3), "At should return the zero value when out of bounds")
+	assert.Equal(t, "", d.At(-1))
+}
+
+func TestDequeRotate(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected []int
+	}{
+		{0, []int{1, 2, 3, 4, 5}},
+		{1, []int{5, 1, 2, 3, 4}},
+		{2, []int{4, 5, 1, 2, 3}},
+		{4, []int{2, 3, 4, 5, 1}},
+		{-1, []int{2, 3, 4, 5, 1}},
+		{7, []int{4, 5, 1, 2, 3}},
+		{-7, []int{3, 4, 5, 1, 2}},
+	}
+	for _, test := range tests {
+		d := ToDeque([]int{1, 2, 3, 4, 5})
+		d.Rotate(test.n)
+		assert.Equal(t, test.expected, d.ToArray(), "Rotate(%d)", test.n)
+	}
+
+	empty := NewDeque[int]()
+	empty.Rotate(3)
+	assert.Equal(t, 0, empty.Count())
+}
+
+func TestDequeIterators(t *testing.T) {
+	d := ToDeque([]int{10, 20, 30})
+	var forward []int
+	for i, item := range d.All() {
+		assert.Equal(t, d.At(i), item)
+		forward = append(forward, item)
+	}
+	assert.Equal(t, []int{10, 20, 30}, forward)
+
+	var backward []int
+	for _, item := range d.Backward() {
+		backward = append(backward, item)
+		if len(backward) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{30, 20}, backward)
+}
+
+func TestDequeContains(t *testing.T) {
+	d := ToDeque([]int{1, 2, 3})
+	assert.True(t, d.Contains(2))
+	assert.False(t, d.Contains(4))
+	assert.True(t, d.ContainsWhere(func(i int) bool { return i > 2 }))
+	assert.False(t, d.ContainsWhere(func(i int) bool { return i > 3 }))
+}
+
+func TestDequeClear(t *testing.T) {
+	d := ToDeque([]int{1, 2, 3})
+	d.Clear()
+	assert.Equal(t, 0, d.Count())
+	assert.Equal(t, List[int]{}, d.ToList())
+}
@@ -1,35 +1,35 @@
 package ectolinq
 
 // Dictionary is a wrapper around a map that provides additional functionality
-type Dictionary[T any] struct {
-	values map[string]T
+type Dictionary[K comparable, V any] struct {
+	values map[K]V
 }
 
 // NewDictionary creates a new dictionary
-func NewDictionary[T any]() *Dictionary[T] {
-	return &Dictionary[T]{
-		values: make(map[string]T),
+func NewDictionary[K comparable, V any]() *Dictionary[K, V] {
+	return &Dictionary[K, V]{
+		values: make(map[K]V),
 	}
 }
 
 // Add a constructor that takes an initial capacity
-func NewDictionaryWithCapacity[T any](capacity int) *Dictionary[T] {
-	return &Dictionary[T]{
-		values: make(map[string]T, capacity),
+func NewDictionaryWithCapacity[K comparable, V any](capacity int) *Dictionary[K, V] {
+	return &Dictionary[K, V]{
+		values: make(map[K]V, capacity),
 	}
 }
 
 // ToDictionary creates a new dictionary from a map
 // m: The map to create the dictionary from
-func ToDictionary[T any](m map[string]T) *Dictionary[T] {
-	return &Dictionary[T]{
+func ToDictionary[K comparable, V any](m map[K]V) *Dictionary[K, V] {
+	return &Dictionary[K, V]{
 		values: m,
 	}
 }
 
 // Get returns the value in the dictionary for the given key
 // key: The key to get the value for
-func (d *Dictionary[T]) Get(key string) (T, bool) {
+func (d *Dictionary[K, V]) Get(key K) (V, bool) {
 	value, ok := d.values[key]
 
 	return value, ok
@@ -38,13 +38,13 @@ func (d *Dictionary[T]) Get(key string) (T, bool) {
 // Set sets the value in the dictionary for the given key
 // key: The key to set the value for
 // value: The value to set
-func (d *Dictionary[T]) Set(key string, value T) {
+func (d *Dictionary[K, V]) Set(key K, value V) {
 	d.values[key] = value
 }
 
 // Keys returns the keys in the dictionary
-func (d *Dictionary[T]) Keys() []string {
-	keys := make([]string, len(d.values))
+func (d *Dictionary[K, V]) Keys() []K {
+	keys := make([]K, len(d.values))
 	i := 0
 	for key := range d.values {
 		keys[i] = key
@@ -56,14 +56,14 @@ func (d *Dictionary[T]) Keys() []string {
 
 // ContainsKey returns if the dictionary contains the given key
 // key: The key to check for
-func (d *Dictionary[T]) ContainsKey(key string) bool {
+func (d *Dictionary[K, V]) ContainsKey(key K) bool {
 	_, ok := d.values[key]
 	return ok
 }
 
 // ContainsValue returns if the dictionary contains the given value
 // value: The value to check for
-func (d *Dictionary[T]) ContainsValue(value T) bool {
+func (d *Dictionary[K, V]) ContainsValue(value V) bool {
 	for _, v := range d.values {
 		if Equals(v, value) {
 			return true
@@ -75,7 +75,7 @@ func (d *Dictionary[T]) ContainsValue(value T) bool {
 
 // ContainsWhere returns if the dictionary contains a value that satisfies the given predicate
 // fn: The predicate to check for
-func (d *Dictionary[T]) ContainsWhere(fn func(string, T) bool) bool {
+func (d *Dictionary[K, V]) ContainsWhere(fn func(K, V) bool) bool {
 	for key, value := range d.values {
 		if fn(key, value) {
 			return true
@@ -87,13 +87,13 @@ func (d *Dictionary[T]) ContainsWhere(fn func(string, T) bool) bool {
 
 // Remove removes the value in the dictionary for the given key
 // key: The key to remove the value for
-func (d *Dictionary[T]) Remove(key string) {
+func (d *Dictionary[K, V]) Remove(key K) {
 	delete(d.values, key)
 }
 
 // RemoveValue removes the given value from the dictionary
 // value: The value to remove
-func (d *Dictionary[T]) RemoveValue(value T) {
+func (d *Dictionary[K, V]) RemoveValue(value V) {
 	for key, v := range d.values {
 		if Equals(v, value) {
 			delete(d.values, key)
@@ -103,7 +103,7 @@ func (d *Dictionary[T]) RemoveValue(value T) {
 
 // RemoveWhere removes the value in the dictionary that satisfies the given predicate
 // fn: The predicate to check for
-func (d *Dictionary[T]) RemoveWhere(fn func(string, T) bool) {
+func (d *Dictionary[K, V]) RemoveWhere(fn func(K, V) bool) {
 	for key, value := range d.values {
 		if fn(key, value) {
 			delete(d.values, key)
@@ -112,7 +112,7 @@ func (d *Dictionary[T]) RemoveWhere(fn func(string, T) bool) {
 }
 
 // Modify the Merge method to return the modified dictionary
-func (d *Dictionary[T]) Merge(dicts ...Dictionary[T]) *Dictionary[T] {
+func (d *Dictionary[K, V]) Merge(dicts ...Dictionary[K, V]) *Dictionary[K, V] {
 	for _, dict := range dicts {
 		for key, value := range dict.values {
 			d.values[key] = value
@@ -123,7 +123,7 @@ func (d *Dictionary[T]) Merge(dicts ...Dictionary[T]) *Dictionary[T] {
 
 // MergeMaps merges the given maps into the dictionary
 // maps: The maps to merge
-func (d *Dictionary[T]) MergeMaps(maps ...map[string]T) {
+func (d *Dictionary[K, V]) MergeMaps(maps ...map[K]V) {
 	for _, m := range maps {
 		for key, value := range m {
 			d.values[key] = value
@@ -132,18 +132,18 @@ func (d *Dictionary[T]) MergeMaps(maps ...map[string]T) {
 }
 
 // Optimize the Clear method
-func (d *Dictionary[T]) Clear() {
-	d.values = make(map[string]T)
+func (d *Dictionary[K, V]) Clear() {
+	d.values = make(map[K]V)
 }
 
 // Count returns the number of values in the dictionary
-func (d *Dictionary[T]) Count() int {
+func (d *Dictionary[K, V]) Count() int {
 	return len(d.values)
 }
 
 // ToArray returns the values in the dictionary as an array
-func (d *Dictionary[T]) ToArray() []T {
-	values := make([]T, len(d.values))
+func (d *Dictionary[K, V]) ToArray() []V {
+	values := make([]V, len(d.values))
 	i := 0
 	for _, value := range d.values {
 		values[i] = value
@@ -154,16 +154,25 @@ func (d *Dictionary[T]) ToArray() []T {
 }
 
 // ToList returns the values in the dictionary as a list
-func (d *Dictionary[T]) ToList() List[T] {
+func (d *Dictionary[K, V]) ToList() List[V] {
 	return d.ToArray()
 }
 
 // ToMap returns the dictionary as a map
-func (d *Dictionary[T]) ToMap() map[string]T {
+func (d *Dictionary[K, V]) ToMap() map[K]V {
 	return d.values
 }
 
 // Add a method to get all values
-func (d *Dictionary[T]) Values() []T {
+func (d *Dictionary[K, V]) Values() []V {
 	return d.ToArray()
 }
+
+// StringDictionary is a Dictionary keyed by strings, the only key type supported before Dictionary took a key type parameter.
+// Existing code can switch Dictionary[T] to StringDictionary[T] and NewDictionary[T]() to NewStringDictionary[T]()
+type StringDictionary[V any] = Dictionary[string, V]
+
+// NewStringDictionary creates a new dictionary keyed by strings
+func NewStringDictionary[V any]() *StringDictionary[V] {
+	return NewDictionary[string, V]()
+}
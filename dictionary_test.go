@@ -8,13 +8,13 @@ import (
 )
 
 func TestNewDictionary(t *testing.T) {
-	d := NewDictionary[int]()
+	d := NewDictionary[string, int]()
 	require.NotNil(t, d, "NewDictionary should not return nil")
 	assert.Empty(t, d.values, "New dictionary should be empty")
 }
 
 func TestNewDictionaryWithCapacity(t *testing.T) {
-	d := NewDictionaryWithCapacity[string](10)
+	d := NewDictionaryWithCapacity[string, string](10)
 	require.NotNil(t, d, "NewDictionaryWithCapacity should not return nil")
 	// Note: We can't directly test the capacity of the underlying map
 }
@@ -26,7 +26,7 @@ func TestToDictionary(t *testing.T) {
 }
 
 func TestDictionaryGetSet(t *testing.T) {
-	d := NewDictionary[int]()
+	d := NewDictionary[string, int]()
 	d.Set("key", 42)
 
 	value, ok := d.Get("key")
@@ -143,3 +143,35 @@ func TestDictionaryValues(t *testing.T) {
 	assert.Contains(t, values, 1, "Values should contain 1")
 	assert.Contains(t, values, 2, "Values should contain 2")
 }
+
+func TestDictionaryNonStringKeys(t *testing.T) {
+	type point struct{ X, Y int }
+
+	d := NewDictionary[point, string]()
+	d.Set(point{1, 2}, "a")
+	d.Set(point{3, 4}, "b")
+
+	value, ok := d.Get(point{1, 2})
+	assert.True(t, ok, "Get should find a composite key")
+	assert.Equal(t, "a", value)
+	assert.ElementsMatch(t, []point{{1, 2}, {3, 4}}, d.Keys(), "Keys should return composite keys")
+	assert.True(t, d.ContainsWhere(func(p point, v string) bool { return p.X == 3 && v == "b" }))
+
+	d.RemoveWhere(func(p point, _ string) bool { return p.X == 1 })
+	assert.False(t, d.ContainsKey(point{1, 2}), "RemoveWhere should remove matching composite keys")
+
+	ids := ToDictionary(map[int]string{1: "one"})
+	ids.Merge(*ToDictionary(map[int]string{2: "two"}))
+	ids.MergeMaps(map[int]string{3: "three"})
+	assert.Equal(t, 3, ids.Count(), "Merge and MergeMaps should work with int keys")
+	assert.ElementsMatch(t, []string{"one", "two", "three"}, ids.ToList())
+}
+
+func TestStringDictionary(t *testing.T) {
+	var d *StringDictionary[int] = NewStringDictionary[int]()
+	d.Set("key", 42)
+	var generic *Dictionary[string, int] = d
+	value, ok := generic.Get("key")
+	assert.True(t, ok, "StringDictionary should be interchangeable with Dictionary[string, V]")
+	assert.Equal(t, 42, value)
+}
@@ -0,0 +1,623 @@
+package ectoiter
+
+import (
+	"iter"
+
+	"github.com/Gobusters/ectolinq"
+)
+
+// From returns a sequence that yields the elements of the slice in order
+// items: The slice to iterate
+func From[T any](items []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// FromMap returns a sequence that yields the key-value pairs of the map in an unspecified order
+// m: The map to iterate
+func FromMap[K comparable, V any](m map[K]V) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, value := range m {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns a sequence of count consecutive integers starting at start
+// start: The first integer to yield
+// count: The number of integers to yield
+func Range(start int, count int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < count; i++ {
+			if !yield(start + i) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate pairs each element of a sequence with its zero-based index
+// seq: The sequence to enumerate
+func Enumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		index := 0
+		for item := range seq {
+			if !yield(index, item) {
+				return
+			}
+			index++
+		}
+	}
+}
+
+// Keys returns a sequence of the keys of a key-value sequence
+// seq: The key-value sequence
+func Keys[K any, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range seq {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of the values of a key-value sequence
+// seq: The key-value sequence
+func Values[K any, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range seq {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Collect drains a sequence into a new slice
+// seq: The sequence to collect
+func Collect[T any](seq iter.Seq[T]) []T {
+	var items []T
+	for item := range seq {
+		items = append(items, item)
+	}
+	return items
+}
+
+// CollectList drains a sequence into a new List
+// seq: The sequence to collect
+func CollectList[T any](seq iter.Seq[T]) ectolinq.List[T] {
+	return ectolinq.ToList(Collect(seq))
+}
+
+// CollectMap drains a key-value sequence into a new map. Later keys overwrite earlier ones
+// seq: The key-value sequence to collect
+func CollectMap[K comparable, V any](seq iter.Seq2[K, V]) map[K]V {
+	m := make(map[K]V)
+	for key, value := range seq {
+		m[key] = value
+	}
+	return m
+}
+
+// Filter returns a sequence of the elements that satisfy the predicate
+// seq: The sequence to filter
+// predicate: The predicate to test each element against
+func Filter[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range seq {
+			if predicate(item) && !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Map projects each element of a sequence into a new form
+// seq: The sequence to map
+// selector: The selector function to use
+func Map[T any, U any](seq iter.Seq[T], selector func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for item := range seq {
+			if !yield(selector(item)) {
+				return
+			}
+		}
+	}
+}
+
+// FlatMap projects each element of a sequence into a slice and flattens the results into one sequence
+// seq: The sequence to map
+// selector: The selector function to use
+func FlatMap[T any, U any](seq iter.Seq[T], selector func(T) []U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for item := range seq {
+			for _, mapped := range selector(item) {
+				if !yield(mapped) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Flatten returns a sequence with the elements of every sub-slice concatenated
+// seq: The sequence of slices to flatten
+func Flatten[T any](seq iter.Seq[[]T]) iter.Seq[T] {
+	return FlatMap(seq, func(items []T) []T { return items })
+}
+
+// Concat returns a sequence that yields every element of each sequence in turn
+// seqs: The sequences to concatenate
+func Concat[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, seq := range seqs {
+			for item := range seq {
+				if !yield(item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Take returns a sequence of the first count elements. The source is not pulled past the last taken element
+// seq: The sequence to take elements from
+// count: The number of elements to take
+func Take[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if count <= 0 {
+			return
+		}
+		taken := 0
+		for item := range seq {
+			if !yield(item) {
+				return
+			}
+			taken++
+			if taken >= count {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile returns a sequence of elements from the start of the sequence while the predicate returns true
+// seq: The sequence to take elements from
+// predicate: The predicate to test each element against
+func TakeWhile[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range seq {
+			if !predicate(item) || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// TakeLast returns a sequence of the last count elements. The whole source is consumed before anything is yielded
+// seq: The sequence to take elements from
+// count: The number of elements to take
+func TakeLast[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if count <= 0 {
+			return
+		}
+		buffer := make([]T, 0, count)
+		start := 0
+		for item := range seq {
+			if len(buffer) < count {
+				buffer = append(buffer, item)
+				continue
+			}
+			buffer[start] = item
+			start = (start + 1) % count
+		}
+		for i := range buffer {
+			if !yield(buffer[(start+i)%len(buffer)]) {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns a sequence with the first count elements removed
+// seq: The sequence to skip elements from
+// count: The number of elements to skip
+func Skip[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		skipped := 0
+		for item := range seq {
+			if skipped < count {
+				skipped++
+				continue
+			}
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// SkipWhile returns a sequence that skips elements while the predicate returns true and then yields the remainder
+// seq: The sequence to skip elements from
+// predicate: The predicate to test each element against
+func SkipWhile[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		skipping := true
+		for item := range seq {
+			if skipping && predicate(item) {
+				continue
+			}
+			skipping = false
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// SkipLast returns a sequence with the last count elements removed. Elements are delayed by count positions
+// seq: The sequence to skip elements from
+// count: The number of elements to skip
+func SkipLast[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	if count <= 0 {
+		return seq
+	}
+	return func(yield func(T) bool) {
+		buffer := make([]T, 0, count)
+		start := 0
+		for item := range seq {
+			if len(buffer) < count {
+				buffer = append(buffer, item)
+				continue
+			}
+			if !yield(buffer[start]) {
+				return
+			}
+			buffer[start] = item
+			start = (start + 1) % count
+		}
+	}
+}
+
+// Reverse returns a sequence of the elements in reverse order. The whole source is consumed before anything is yielded
+// seq: The sequence to reverse
+func Reverse[T any](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		items := Collect(seq)
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Distinct returns a sequence of the distinct elements in first-seen order
+// seq: The sequence to search
+func Distinct[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return DistinctBy(seq, func(item T) T { return item })
+}
+
+// DistinctBy returns a sequence of the elements with distinct keys in first-seen order
+// seq: The sequence to search
+// keySelector: The function to extract the key from each element
+func DistinctBy[T any, U comparable](seq iter.Seq[T], keySelector func(T) U) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[U]struct{})
+		for item := range seq {
+			key := keySelector(item)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Except returns a sequence of the elements that do not appear in other. other is consumed when iteration starts
+// seq: The sequence to search
+// other: The sequence whose elements will be removed from the result
+func Except[T comparable](seq iter.Seq[T], other iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		otherSet := toSet(other)
+		for item := range seq {
+			if _, ok := otherSet[item]; !ok && !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Intersect returns a sequence of the distinct elements that also appear in other. other is consumed when iteration starts
+// seq: The sequence to search
+// other: The sequence whose elements must also appear
+func Intersect[T comparable](seq iter.Seq[T], other iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		otherSet := toSet(other)
+		for item := range seq {
+			if _, ok := otherSet[item]; !ok {
+				continue
+			}
+			delete(otherSet, item)
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns a sequence of the distinct elements that appear in either sequence
+// seq: The first sequence
+// other: The second sequence
+func Union[T comparable](seq iter.Seq[T], other iter.Seq[T]) iter.Seq[T] {
+	return Distinct(Concat(seq, other))
+}
+
+// Chunk returns a sequence of slices of the specified size. The last chunk may be smaller
+// seq: The sequence to chunk
+// size: The size of each chunk
+func Chunk[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			return
+		}
+		chunk := make([]T, 0, size)
+		for item := range seq {
+			chunk = append(chunk, item)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Zip combines two sequences pairwise using a selector function, stopping at the end of the shorter sequence
+// seq: The first sequence
+// other: The second sequence
+// selector: The selector function to use
+func Zip[T any, U any, V any](seq iter.Seq[T], other iter.Seq[U], selector func(T, U) V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		next, stop := iter.Pull(other)
+		defer stop()
+		for item := range seq {
+			otherItem, ok := next()
+			if !ok || !yield(selector(item, otherItem)) {
+				return
+			}
+		}
+	}
+}
+
+// ForEach performs the specified action on each element of a sequence
+// seq: The sequence to iterate
+// action: The action to perform on each element
+func ForEach[T any](seq iter.Seq[T], action func(T)) {
+	for item := range seq {
+		action(item)
+	}
+}
+
+// Find returns the first element that satisfies the predicate and whether one was found
+// seq: The sequence to search
+// predicate: The predicate to test each element against
+func Find[T any](seq iter.Seq[T], predicate func(T) bool) (T, bool) {
+	for item := range seq {
+		if predicate(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndexWhere returns the index of the first element that satisfies the predicate, or -1
+// seq: The sequence to search
+// predicate: The predicate to test each element against
+func FindIndexWhere[T any](seq iter.Seq[T], predicate func(T) bool) int {
+	for index, item := range Enumerate(seq) {
+		if predicate(item) {
+			return index
+		}
+	}
+	return -1
+}
+
+// First returns the first element of a sequence and whether the sequence was non-empty
+// seq: The sequence to get the first element from
+func First[T any](seq iter.Seq[T]) (T, bool) {
+	for item := range seq {
+		return item, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Last returns the last element of a sequence and whether the sequence was non-empty
+// seq: The sequence to get the last element from
+func Last[T any](seq iter.Seq[T]) (T, bool) {
+	var last T
+	found := false
+	for item := range seq {
+		last = item
+		found = true
+	}
+	return last, found
+}
+
+// Contains determines whether a sequence contains a specific value
+// seq: The sequence to search
+// value: The value to locate
+func Contains[T comparable](seq iter.Seq[T], value T) bool {
+	return Any(seq, func(item T) bool { return item == value })
+}
+
+// Any determines whether any element satisfies a condition. Iteration stops at the first match
+// seq: The sequence to search
+// predicate: The predicate to test each element against
+func Any[T any](seq iter.Seq[T], predicate func(T) bool) bool {
+	_, found := Find(seq, predicate)
+	return found
+}
+
+// All determines whether all elements satisfy a condition. Iteration stops at the first failure
+// seq: The sequence to search
+// predicate: The predicate to test each element against
+func All[T any](seq iter.Seq[T], predicate func(T) bool) bool {
+	return !Any(seq, func(item T) bool { return !predicate(item) })
+}
+
+// Count returns the number of elements that satisfy a condition
+// seq: The sequence to search
+// predicate: The predicate to test each element against
+func Count[T any](seq iter.Seq[T], predicate func(T) bool) int {
+	count := 0
+	for item := range seq {
+		if predicate(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// Reduce applies an accumulator function over a sequence
+// seq: The sequence to reduce
+// accumulator: The accumulator function to use
+func Reduce[T any, U any](seq iter.Seq[T], accumulator func(U, T) U, initialValue ...U) U {
+	var result U
+	if len(initialValue) > 0 {
+		result = initialValue[0]
+	}
+	for item := range seq {
+		result = accumulator(result, item)
+	}
+	return result
+}
+
+// Sum returns the sum of all elements in a sequence
+// seq: The sequence to sum
+func Sum[T int | int32 | int64 | float32 | float64](seq iter.Seq[T]) T {
+	var sum T
+	for item := range seq {
+		sum += item
+	}
+	return sum
+}
+
+// Average returns the average of all elements in a sequence, or 0 for an empty sequence
+// seq: The sequence to average
+func Average[T int | int32 | int64 | float32 | float64](seq iter.Seq[T]) float64 {
+	var sum T
+	count := 0
+	for item := range seq {
+		sum += item
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+// Min returns the minimum value in a sequence and whether the sequence was non-empty
+// seq: The sequence to get the minimum value from
+func Min[T int | int32 | int64 | float32 | float64](seq iter.Seq[T]) (T, bool) {
+	return extreme(seq, func(a, b T) bool { return a < b })
+}
+
+// Max returns the maximum value in a sequence and whether the sequence was non-empty
+// seq: The sequence to get the maximum value from
+func Max[T int | int32 | int64 | float32 | float64](seq iter.Seq[T]) (T, bool) {
+	return extreme(seq, func(a, b T) bool { return a > b })
+}
+
+// SequenceEqual determines whether two sequences yield equal elements in the same order
+// seq: The first sequence to compare
+// other: The second sequence to compare
+func SequenceEqual[T any](seq iter.Seq[T], other iter.Seq[T]) bool {
+	next, stop := iter.Pull(other)
+	defer stop()
+	for item := range seq {
+		otherItem, ok := next()
+		if !ok || !ectolinq.Equals(item, otherItem) {
+			return false
+		}
+	}
+	_, ok := next()
+	return !ok
+}
+
+// Partition splits a sequence into two slices based on a predicate
+// seq: The sequence to partition
+// predicate: The predicate to test each element against
+func Partition[T any](seq iter.Seq[T], predicate func(T) bool) ([]T, []T) {
+	var trueItems, falseItems []T
+	for item := range seq {
+		if predicate(item) {
+			trueItems = append(trueItems, item)
+		} else {
+			falseItems = append(falseItems, item)
+		}
+	}
+	return trueItems, falseItems
+}
+
+// KeyWhere returns a map of the sequence where the key is the result of the selector function
+// seq: The sequence to convert to a map
+// selector: The selector function to use
+func KeyWhere[T any, U comparable](seq iter.Seq[T], selector func(T) U) map[U]T {
+	key := make(map[U]T)
+	for item := range seq {
+		key[selector(item)] = item
+	}
+	return key
+}
+
+// GroupWhere returns a map of the sequence where the key is the result of the selector function and the value is a slice of all the elements that match the key
+// seq: The sequence to convert to a map
+// selector: The selector function to use
+func GroupWhere[T any, U comparable](seq iter.Seq[T], selector func(T) U) map[U][]T {
+	groups := make(map[U][]T)
+	for item := range seq {
+		key := selector(item)
+		groups[key] = append(groups[key], item)
+	}
+	return groups
+}
+
+func toSet[T comparable](seq iter.Seq[T]) map[T]struct{} {
+	set := make(map[T]struct{})
+	for item := range seq {
+		set[item] = struct{}{}
+	}
+	return set
+}
+
+func extreme[T any](seq iter.Seq[T], better func(a, b T) bool) (T, bool) {
+	var result T
+	found := false
+	for item := range seq {
+		if !found || better(item, result) {
+			result = item
+			found = true
+		}
+	}
+	return result, found
+}
@@ -0,0 +1,259 @@
+package ectoiter
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/Gobusters/ectolinq"
+	"github.com/stretchr/testify/assert"
+)
+
+// counting wraps a sequence and records how many elements were pulled from it
+func counting[T any](seq iter.Seq[T], pulled *int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for item := range seq {
+			*pulled++
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	t.Run("Collect slice", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, Collect(From([]int{1, 2, 3})))
+	})
+
+	t.Run("Collect list", func(t *testing.T) {
+		list := CollectList(From([]string{"a", "b"}))
+		assert.Equal(t, ectolinq.ToList([]string{"a", "b"}), list)
+	})
+
+	t.Run("Collect empty", func(t *testing.T) {
+		assert.Empty(t, Collect(From([]int{})))
+	})
+
+	t.Run("Collect map", func(t *testing.T) {
+		m := CollectMap(Enumerate(From([]string{"a", "b"})))
+		assert.Equal(t, map[int]string{0: "a", 1: "b"}, m)
+	})
+}
+
+func TestFilterMapTake(t *testing.T) {
+	t.Run("Pipeline matches eager functions", func(t *testing.T) {
+		items := Collect(Range(0, 100))
+		isEven := func(n int) bool { return n%2 == 0 }
+		square := func(n int) int { return n * n }
+
+		lazy := Collect(Take(Map(Filter(From(items), isEven), square), 10))
+		eager := ectolinq.Take(ectolinq.Map(ectolinq.Filter(items, isEven), square), 10)
+		assert.Equal(t, eager, lazy)
+	})
+
+	t.Run("Take stops pulling from the source", func(t *testing.T) {
+		pulled := 0
+		result := Collect(Take(Filter(counting(Range(0, 1000000), &pulled), func(n int) bool { return n%2 == 0 }), 3))
+		assert.Equal(t, []int{0, 2, 4}, result)
+		assert.Equal(t, 5, pulled)
+	})
+
+	t.Run("Take zero", func(t *testing.T) {
+		pulled := 0
+		assert.Empty(t, Collect(Take(counting(Range(0, 10), &pulled), 0)))
+		assert.Equal(t, 0, pulled)
+	})
+}
+
+func TestShortCircuit(t *testing.T) {
+	t.Run("First", func(t *testing.T) {
+		pulled := 0
+		first, ok := First(counting(Range(5, 100), &pulled))
+		assert.True(t, ok)
+		assert.Equal(t, 5, first)
+		assert.Equal(t, 1, pulled)
+	})
+
+	t.Run("First of empty", func(t *testing.T) {
+		_, ok := First(From([]int{}))
+		assert.False(t, ok)
+	})
+
+	t.Run("Any", func(t *testing.T) {
+		pulled := 0
+		assert.True(t, Any(counting(Range(0, 100), &pulled), func(n int) bool { return n == 3 }))
+		assert.Equal(t, 4, pulled)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		pulled := 0
+		assert.False(t, All(counting(Range(0, 100), &pulled), func(n int) bool { return n < 2 }))
+		assert.Equal(t, 3, pulled)
+	})
+}
+
+func TestSkipAndTakeVariants(t *testing.T) {
+	items := From([]int{1, 2, 3, 4, 5, 1})
+
+	t.Run("Skip", func(t *testing.T) {
+		assert.Equal(t, []int{4, 5, 1}, Collect(Skip(items, 3)))
+	})
+
+	t.Run("SkipWhile only skips the leading run", func(t *testing.T) {
+		assert.Equal(t, []int{3, 4, 5, 1}, Collect(SkipWhile(items, func(n int) bool { return n < 3 })))
+	})
+
+	t.Run("TakeWhile", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2}, Collect(TakeWhile(items, func(n int) bool { return n < 3 })))
+	})
+
+	t.Run("TakeLast", func(t *testing.T) {
+		assert.Equal(t, []int{4, 5, 1}, Collect(TakeLast(items, 3)))
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 1}, Collect(TakeLast(items, 10)))
+	})
+
+	t.Run("SkipLast", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, Collect(SkipLast(items, 3)))
+		assert.Empty(t, Collect(SkipLast(items, 10)))
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		assert.Equal(t, []int{1, 5, 4, 3, 2, 1}, Collect(Reverse(items)))
+	})
+}
+
+func TestSetOperations(t *testing.T) {
+	t.Run("Distinct", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, Collect(Distinct(From([]int{1, 2, 2, 3, 1}))))
+	})
+
+	t.Run("DistinctBy", func(t *testing.T) {
+		result := Collect(DistinctBy(From([]string{"apple", "avocado", "banana"}), func(s string) byte { return s[0] }))
+		assert.Equal(t, []string{"apple", "banana"}, result)
+	})
+
+	t.Run("Except", func(t *testing.T) {
+		assert.Equal(t, []int{1, 3}, Collect(Except(From([]int{1, 2, 3, 4}), From([]int{2, 4}))))
+	})
+
+	t.Run("Intersect", func(t *testing.T) {
+		assert.Equal(t, []int{2, 4}, Collect(Intersect(From([]int{1, 2, 2, 3, 4}), From([]int{4, 2}))))
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3, 4}, Collect(Union(From([]int{1, 2, 2}), From([]int{2, 3, 4}))))
+	})
+}
+
+func TestChunkAndFlatten(t *testing.T) {
+	t.Run("Chunk", func(t *testing.T) {
+		chunks := Collect(Chunk(Range(1, 5), 2))
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+	})
+
+	t.Run("Flatten", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, Collect(Flatten(Chunk(Range(1, 5), 2))))
+	})
+
+	t.Run("FlatMap", func(t *testing.T) {
+		result := Collect(FlatMap(From([]int{1, 2}), func(n int) []int { return []int{n, n * 10} }))
+		assert.Equal(t, []int{1, 10, 2, 20}, result)
+	})
+}
+
+func TestZip(t *testing.T) {
+	t.Run("Zip stops at the shorter sequence", func(t *testing.T) {
+		result := Collect(Zip(From([]int{1, 2, 3}), From([]string{"a", "b"}), func(n int, s string) string {
+			return s + string(rune('0'+n))
+		}))
+		assert.Equal(t, []string{"a1", "b2"}, result)
+	})
+}
+
+func TestAggregates(t *testing.T) {
+	items := From([]int{3, 1, 4, 1, 5})
+
+	t.Run("Sum and Average", func(t *testing.T) {
+		assert.Equal(t, 14, Sum(items))
+		assert.InDelta(t, 2.8, Average(items), 0.0001)
+		assert.Equal(t, 0.0, Average(From([]int{})))
+	})
+
+	t.Run("Min and Max", func(t *testing.T) {
+		min, ok := Min(items)
+		assert.True(t, ok)
+		assert.Equal(t, 1, min)
+		max, ok := Max(items)
+		assert.True(t, ok)
+		assert.Equal(t, 5, max)
+		_, ok = Max(From([]int{}))
+		assert.False(t, ok)
+	})
+
+	t.Run("Reduce", func(t *testing.T) {
+		product := Reduce(items, func(acc int, n int) int { return acc * n }, 1)
+		assert.Equal(t, 60, product)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		assert.Equal(t, 2, Count(items, func(n int) bool { return n == 1 }))
+	})
+
+	t.Run("Last", func(t *testing.T) {
+		last, ok := Last(items)
+		assert.True(t, ok)
+		assert.Equal(t, 5, last)
+	})
+
+	t.Run("Find and FindIndexWhere", func(t *testing.T) {
+		found, ok := Find(items, func(n int) bool { return n > 3 })
+		assert.True(t, ok)
+		assert.Equal(t, 4, found)
+		assert.Equal(t, 2, FindIndexWhere(items, func(n int) bool { return n > 3 }))
+		assert.Equal(t, -1, FindIndexWhere(items, func(n int) bool { return n > 10 }))
+	})
+
+	t.Run("Contains", func(t *testing.T) {
+		assert.True(t, Contains(items, 4))
+		assert.False(t, Contains(items, 9))
+	})
+}
+
+func TestSequenceEqual(t *testing.T) {
+	assert.True(t, SequenceEqual(From([]int{1, 2}), Range(1, 2)))
+	assert.False(t, SequenceEqual(From([]int{1, 2}), Range(1, 3)))
+	assert.False(t, SequenceEqual(From([]int{1, 2, 3}), Range(1, 2)))
+}
+
+func TestGrouping(t *testing.T) {
+	words := From([]string{"apple", "avocado", "banana"})
+
+	t.Run("GroupWhere", func(t *testing.T) {
+		groups := GroupWhere(words, func(s string) byte { return s[0] })
+		assert.Equal(t, map[byte][]string{'a': {"apple", "avocado"}, 'b': {"banana"}}, groups)
+	})
+
+	t.Run("KeyWhere", func(t *testing.T) {
+		keyed := KeyWhere(words, func(s string) int { return len(s) })
+		assert.Equal(t, map[int]string{5: "apple", 7: "avocado", 6: "banana"}, keyed)
+	})
+
+	t.Run("Partition", func(t *testing.T) {
+		long, short := Partition(words, func(s string) bool { return len(s) > 5 })
+		assert.Equal(t, []string{"avocado", "banana"}, long)
+		assert.Equal(t, []string{"apple"}, short)
+	})
+}
+
+func TestSeq2Helpers(t *testing.T) {
+	t.Run("Keys and Values", func(t *testing.T) {
+		pairs := Enumerate(From([]string{"a", "b"}))
+		assert.Equal(t, []int{0, 1}, Collect(Keys(pairs)))
+		assert.Equal(t, []string{"a", "b"}, Collect(Values(pairs)))
+	})
+
+	t.Run("FromMap", func(t *testing.T) {
+		m := map[string]int{"a": 1, "b": 2}
+		assert.Equal(t, m, CollectMap(FromMap(m)))
+	})
+}
@@ -0,0 +1,282 @@
+package ectoiter
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/Gobusters/ectolinq"
+)
+
+// Query is a lazily evaluated, chainable query over a sequence.
+// Operations that keep the element type are methods; operations that change it (Select, SelectMany, GroupBy...)
+// are package functions that take the query as their first argument, since Go methods cannot declare type parameters
+type Query[T any] struct {
+	seq iter.Seq[T]
+}
+
+// OrderedQuery is a Query whose results are stably sorted by one or more keys. Use ThenBy and ThenByDescending to break ties
+type OrderedQuery[T any] struct {
+	Query[T]
+	source iter.Seq[T]
+	keys   []ectolinq.SortKey[T]
+}
+
+// Queryable is implemented by Query and OrderedQuery, so the package functions that take a query accept either
+type Queryable[T any] interface {
+	Seq() iter.Seq[T]
+}
+
+// Grouping is a key and the elements that share it, produced by GroupBy
+type Grouping[K comparable, T any] struct {
+	Key   K
+	Items []T
+}
+
+// ToQuery creates a new query over the elements of a slice
+// items: The slice to query
+func ToQuery[T any](items []T) Query[T] {
+	return Query[T]{seq: From(items)}
+}
+
+// FromSeq creates a new query over a sequence
+// seq: The sequence to query
+func FromSeq[T any](seq iter.Seq[T]) Query[T] {
+	return Query[T]{seq: seq}
+}
+
+// Seq returns the query as a sequence
+func (q Query[T]) Seq() iter.Seq[T] {
+	if q.seq == nil {
+		return func(func(T) bool) {}
+	}
+	return q.seq
+}
+
+// Where filters the query to the elements that satisfy the predicate
+// predicate: The predicate to test each element against
+func (q Query[T]) Where(predicate func(T) bool) Query[T] {
+	return FromSeq(Filter(q.Seq(), predicate))
+}
+
+// Take limits the query to the first count elements
+// count: The number of elements to take
+func (q Query[T]) Take(count int) Query[T] {
+	return FromSeq(Take(q.Seq(), count))
+}
+
+// TakeWhile limits the query to the elements at the start that satisfy the predicate
+// predicate: The predicate to test each element against
+func (q Query[T]) TakeWhile(predicate func(T) bool) Query[T] {
+	return FromSeq(TakeWhile(q.Seq(), predicate))
+}
+
+// TakeLast limits the query to the last count elements
+// count: The number of elements to take
+func (q Query[T]) TakeLast(count int) Query[T] {
+	return FromSeq(TakeLast(q.Seq(), count))
+}
+
+// Skip removes the first count elements from the query
+// count: The number of elements to skip
+func (q Query[T]) Skip(count int) Query[T] {
+	return FromSeq(Skip(q.Seq(), count))
+}
+
+// SkipWhile removes the elements at the start that satisfy the predicate
+// predicate: The predicate to test each element against
+func (q Query[T]) SkipWhile(predicate func(T) bool) Query[T] {
+	return FromSeq(SkipWhile(q.Seq(), predicate))
+}
+
+// SkipLast removes the last count elements from the query
+// count: The number of elements to skip
+func (q Query[T]) SkipLast(count int) Query[T] {
+	return FromSeq(SkipLast(q.Seq(), count))
+}
+
+// Reverse reverses the order of the elements in the query
+func (q Query[T]) Reverse() Query[T] {
+	return FromSeq(Reverse(q.Seq()))
+}
+
+// Concat appends the elements of another query
+// other: The query to append
+func (q Query[T]) Concat(other Queryable[T]) Query[T] {
+	return FromSeq(Concat(q.Seq(), other.Seq()))
+}
+
+// OrderBy stably sorts the results ascending by the given key. The source is consumed when iteration starts
+// key: The key to sort by, usually created with ectolinq.By
+func (q Query[T]) OrderBy(key ectolinq.SortKey[T]) OrderedQuery[T] {
+	return orderQuery(q.Seq(), key)
+}
+
+// OrderByDescending stably sorts the results descending by the given key. The source is consumed when iteration starts
+// key: The key to sort by, usually created with ectolinq.By
+func (q Query[T]) OrderByDescending(key ectolinq.SortKey[T]) OrderedQuery[T] {
+	return orderQuery(q.Seq(), key.Reverse())
+}
+
+// ThenBy breaks ties ascending by the given key
+// key: The key to sort by, usually created with ectolinq.By
+func (q OrderedQuery[T]) ThenBy(key ectolinq.SortKey[T]) OrderedQuery[T] {
+	// A zero OrderedQuery has no source, so go through Seq to treat it as empty like a zero Query
+	return orderQuery(FromSeq(q.source).Seq(), append(slices.Clip(q.keys), key)...)
+}
+
+// ThenByDescending breaks ties descending by the given key
+// key: The key to sort by, usually created with ectolinq.By
+func (q OrderedQuery[T]) ThenByDescending(key ectolinq.SortKey[T]) OrderedQuery[T] {
+	return q.ThenBy(key.Reverse())
+}
+
+// Then applies a sequence operator that keeps the element type, such as any function in this package
+// op: The operator to apply
+func (q Query[T]) Then(op func(iter.Seq[T]) iter.Seq[T]) Query[T] {
+	return FromSeq(op(q.Seq()))
+}
+
+// ToSlice runs the query and returns the results as a slice
+func (q Query[T]) ToSlice() []T {
+	return Collect(q.Seq())
+}
+
+// ToList runs the query and returns the results as a List
+func (q Query[T]) ToList() ectolinq.List[T] {
+	return CollectList(q.Seq())
+}
+
+// ForEach runs the query and performs the specified action on each result
+// action: The action to perform on each element
+func (q Query[T]) ForEach(action func(T)) {
+	ForEach(q.Seq(), action)
+}
+
+// First returns the first result and whether the query produced any
+func (q Query[T]) First() (T, bool) {
+	return First(q.Seq())
+}
+
+// Last returns the last result and whether the query produced any
+func (q Query[T]) Last() (T, bool) {
+	return Last(q.Seq())
+}
+
+// Find returns the first result that satisfies the predicate and whether one was found
+// predicate: The predicate to test each element against
+func (q Query[T]) Find(predicate func(T) bool) (T, bool) {
+	return Find(q.Seq(), predicate)
+}
+
+// Any determines whether any result satisfies a condition
+// predicate: The predicate to test each element against
+func (q Query[T]) Any(predicate func(T) bool) bool {
+	return Any(q.Seq(), predicate)
+}
+
+// All determines whether all results satisfy a condition
+// predicate: The predicate to test each element against
+func (q Query[T]) All(predicate func(T) bool) bool {
+	return All(q.Seq(), predicate)
+}
+
+// Count returns the number of results that satisfy a condition
+// predicate: The predicate to test each element against
+func (q Query[T]) Count(predicate func(T) bool) int {
+	return Count(q.Seq(), predicate)
+}
+
+// Length returns the number of results
+func (q Query[T]) Length() int {
+	return Count(q.Seq(), func(T) bool { return true })
+}
+
+// Select projects each element of a query into a new form
+// q: The query to project
+// selector: The selector function to use
+func Select[T any, U any](q Queryable[T], selector func(T) U) Query[U] {
+	return FromSeq(Map(q.Seq(), selector))
+}
+
+// SelectMany projects each element of a query into a slice and flattens the results
+// q: The query to project
+// selector: The selector function to use
+func SelectMany[T any, U any](q Queryable[T], selector func(T) []U) Query[U] {
+	return FromSeq(FlatMap(q.Seq(), selector))
+}
+
+// Pipe applies a sequence operator that may change the element type
+// q: The query to transform
+// op: The operator to apply
+func Pipe[T any, U any](q Queryable[T], op func(iter.Seq[T]) iter.Seq[U]) Query[U] {
+	return FromSeq(op(q.Seq()))
+}
+
+// GroupBy groups the elements of a query by key. Groups are produced in the order their keys are first seen.
+// The source is consumed when iteration of the groups starts
+// q: The query to group
+// keySelector: The function to extract the key from each element
+func GroupBy[T any, K comparable](q Queryable[T], keySelector func(T) K) Query[Grouping[K, T]] {
+	return FromSeq(func(yield func(Grouping[K, T]) bool) {
+		var keys []K
+		groups := make(map[K][]T)
+		for item := range q.Seq() {
+			key := keySelector(item)
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], item)
+		}
+		for _, key := range keys {
+			if !yield(Grouping[K, T]{Key: key, Items: groups[key]}) {
+				return
+			}
+		}
+	})
+}
+
+// DistinctQuery removes duplicate elements from a query, keeping the first occurrence
+// q: The query to search
+func DistinctQuery[T comparable](q Queryable[T]) Query[T] {
+	return FromSeq(Distinct(q.Seq()))
+}
+
+// DistinctQueryBy removes elements with duplicate keys from a query, keeping the first occurrence
+// q: The query to search
+// keySelector: The function to extract the key from each element
+func DistinctQueryBy[T any, K comparable](q Queryable[T], keySelector func(T) K) Query[T] {
+	return FromSeq(DistinctBy(q.Seq(), keySelector))
+}
+
+// ZipQuery combines two queries pairwise using a selector function
+// q: The first query
+// other: The second query
+// selector: The selector function to use
+func ZipQuery[T any, U any, V any](q Queryable[T], other Queryable[U], selector func(T, U) V) Query[V] {
+	return FromSeq(Zip(q.Seq(), other.Seq(), selector))
+}
+
+// Aggregate runs the query and applies an accumulator function over the results
+// q: The query to reduce
+// seed: The initial accumulator value
+// accumulator: The accumulator function to use
+func Aggregate[T any, U any](q Queryable[T], seed U, accumulator func(U, T) U) U {
+	return Reduce(q.Seq(), accumulator, seed)
+}
+
+func orderQuery[T any](source iter.Seq[T], keys ...ectolinq.SortKey[T]) OrderedQuery[T] {
+	sorted := func(yield func(T) bool) {
+		items := Collect(source)
+		slices.SortStableFunc(items, ectolinq.CompareKeys(keys...))
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+	return OrderedQuery[T]{
+		Query:  FromSeq(sorted),
+		source: source,
+		keys:   keys,
+	}
+}
@@ -0,0 +1,175 @@
+package ectoiter
+
+import (
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/Gobusters/ectolinq"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name string
+	Age  int
+	City string
+}
+
+var people = []person{
+	{Name: "Ada", Age: 36, City: "London"},
+	{Name: "Grace", Age: 45, City: "New York"},
+	{Name: "Alan", Age: 41, City: "London"},
+	{Name: "Linus", Age: 21, City: "Helsinki"},
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("Where and ToSlice", func(t *testing.T) {
+		result := ToQuery(people).Where(func(p person) bool { return p.Age > 40 }).ToSlice()
+		assert.Equal(t, []person{people[1], people[2]}, result)
+	})
+
+	t.Run("Select changes the element type", func(t *testing.T) {
+		names := Select(ToQuery(people).Where(func(p person) bool { return p.City == "London" }), func(p person) string {
+			return p.Name
+		}).ToList()
+		assert.Equal(t, ectolinq.ToList([]string{"Ada", "Alan"}), names)
+	})
+
+	t.Run("GroupBy keeps first-seen key order", func(t *testing.T) {
+		groups := GroupBy(ToQuery(people), func(p person) string { return p.City }).ToSlice()
+		assert.Len(t, groups, 3)
+		assert.Equal(t, "London", groups[0].Key)
+		assert.Equal(t, []person{people[0], people[2]}, groups[0].Items)
+		assert.Equal(t, "New York", groups[1].Key)
+		assert.Equal(t, "Helsinki", groups[2].Key)
+	})
+
+	t.Run("Where, Select, GroupBy and Select again", func(t *testing.T) {
+		adults := ToQuery(people).Where(func(p person) bool { return p.Age > 30 })
+		byCity := GroupBy(adults, func(p person) string { return p.City })
+		summary := Select(byCity, func(g Grouping[string, person]) string {
+			return g.Key + ":" + strings.Join(Select(ToQuery(g.Items), func(p person) string { return p.Name }).ToSlice(), ",")
+		}).ToSlice()
+		assert.Equal(t, []string{"London:Ada,Alan", "New York:Grace"}, summary)
+	})
+
+	t.Run("SelectMany", func(t *testing.T) {
+		letters := SelectMany(ToQuery([]string{"ab", "c"}), func(s string) []rune { return []rune(s) }).ToSlice()
+		assert.Equal(t, []rune{'a', 'b', 'c'}, letters)
+	})
+
+	t.Run("Query is lazy", func(t *testing.T) {
+		pulled := 0
+		q := FromSeq(counting(Range(0, 1000), &pulled)).Where(func(n int) bool { return n > 10 }).Take(2)
+		assert.Equal(t, 0, pulled)
+		assert.Equal(t, []int{11, 12}, q.ToSlice())
+		assert.Equal(t, 13, pulled)
+	})
+
+	t.Run("Query can be run more than once", func(t *testing.T) {
+		q := ToQuery([]int{1, 2, 3}).Skip(1)
+		assert.Equal(t, []int{2, 3}, q.ToSlice())
+		assert.Equal(t, []int{2, 3}, q.ToSlice())
+	})
+
+	t.Run("Zero query is empty", func(t *testing.T) {
+		var q Query[int]
+		assert.Empty(t, q.ToSlice())
+		assert.Equal(t, 0, q.Length())
+	})
+
+	t.Run("Distinct and DistinctBy", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2}, DistinctQuery(ToQuery([]int{1, 2, 1})).ToSlice())
+		cities := DistinctQueryBy(ToQuery(people), func(p person) string { return p.City }).Length()
+		assert.Equal(t, 3, cities)
+	})
+
+	t.Run("Zip and Aggregate", func(t *testing.T) {
+		ages := Select(ToQuery(people), func(p person) int { return p.Age })
+		weights := ToQuery([]int{1, 0, 0, 2})
+		total := Aggregate(ZipQuery(ages, weights, func(a, w int) int { return a * w }), 0, func(acc, n int) int { return acc + n })
+		assert.Equal(t, 36+42, total)
+	})
+
+	t.Run("Then and Pipe apply sequence operators", func(t *testing.T) {
+		chunks := Pipe(ToQuery([]int{1, 2, 3, 4, 5}).Then(func(s iter.Seq[int]) iter.Seq[int] {
+			return Skip(s, 1)
+		}), func(s iter.Seq[int]) iter.Seq[[]int] {
+			return Chunk(s, 2)
+		}).ToSlice()
+		assert.Equal(t, [][]int{{2, 3}, {4, 5}}, chunks)
+	})
+
+	t.Run("Terminal operators", func(t *testing.T) {
+		q := ToQuery([]int{4, 8, 15, 16, 23, 42})
+		first, ok := q.First()
+		assert.True(t, ok)
+		assert.Equal(t, 4, first)
+		last, ok := q.Last()
+		assert.True(t, ok)
+		assert.Equal(t, 42, last)
+		found, ok := q.Find(func(n int) bool { return n%2 == 1 })
+		assert.True(t, ok)
+		assert.Equal(t, 15, found)
+		assert.True(t, q.Any(func(n int) bool { return n > 40 }))
+		assert.False(t, q.All(func(n int) bool { return n%2 == 0 }))
+		assert.Equal(t, 4, q.Count(func(n int) bool { return n%2 == 0 }))
+		assert.Equal(t, []int{42, 23}, q.Reverse().Take(2).ToSlice())
+		assert.Equal(t, []int{23, 42}, q.TakeLast(2).ToSlice())
+		assert.Equal(t, []int{4, 8}, q.SkipLast(4).ToSlice())
+		assert.Equal(t, []int{4, 8}, q.TakeWhile(func(n int) bool { return n < 10 }).ToSlice())
+		assert.Equal(t, 4, q.SkipWhile(func(n int) bool { return n < 10 }).Length())
+		assert.Equal(t, 8, q.Concat(ToQuery([]int{1, 2})).Length())
+
+		sum := 0
+		q.ForEach(func(n int) { sum += n })
+		assert.Equal(t, 108, sum)
+	})
+}
+
+func TestQueryOrderBy(t *testing.T) {
+	t.Run("Where, OrderBy and ThenBy", func(t *testing.T) {
+		names := Select(ToQuery(people).
+			Where(func(p person) bool { return p.Age > 30 }).
+			OrderBy(ectolinq.By(func(p person) string { return p.City })).
+			ThenByDescending(ectolinq.By(func(p person) int { return p.Age })),
+			func(p person) string { return p.Name }).ToSlice()
+		assert.Equal(t, []string{"Alan", "Ada", "Grace"}, names)
+	})
+
+	t.Run("OrderByDescending", func(t *testing.T) {
+		ages := Select(ToQuery(people).OrderByDescending(ectolinq.By(func(p person) int { return p.Age })), func(p person) int {
+			return p.Age
+		}).ToSlice()
+		assert.Equal(t, []int{45, 41, 36, 21}, ages)
+	})
+
+	t.Run("Package functions accept an ordered query", func(t *testing.T) {
+		byAge := ToQuery(people).OrderBy(ectolinq.By(func(p person) int { return p.Age }))
+		groups := GroupBy(byAge, func(p person) bool { return p.Age > 30 })
+		assert.Equal(t, []bool{false, true}, Select(groups, func(g Grouping[bool, person]) bool { return g.Key }).ToSlice())
+		assert.Equal(t, 143, Aggregate(byAge, 0, func(total int, p person) int { return total + p.Age }))
+		names := Pipe(byAge, func(seq iter.Seq[person]) iter.Seq[string] {
+			return Map(seq, func(p person) string { return p.Name })
+		})
+		assert.Equal(t, []string{"Linus", "Ada", "Alan", "Grace"}, names.ToSlice())
+	})
+
+	t.Run("ThenBy on a zero ordered query is empty", func(t *testing.T) {
+		var q OrderedQuery[int]
+		assert.Empty(t, q.ThenBy(ectolinq.By(func(n int) int { return n })).ToSlice())
+		assert.Empty(t, q.ThenByDescending(ectolinq.By(func(n int) int { return n })).ToSlice())
+	})
+
+	t.Run("Concat accepts an ordered query", func(t *testing.T) {
+		sorted := ToQuery([]int{3, 1, 2}).OrderBy(ectolinq.By(func(n int) int { return n }))
+		assert.Equal(t, []int{0, 1, 2, 3}, ToQuery([]int{0}).Concat(sorted).ToSlice())
+	})
+
+	t.Run("Ordering is lazy", func(t *testing.T) {
+		pulled := 0
+		q := FromSeq(counting(Range(0, 5), &pulled)).OrderByDescending(ectolinq.By(func(n int) int { return n }))
+		assert.Equal(t, 0, pulled)
+		assert.Equal(t, []int{4, 3}, q.Take(2).ToSlice())
+	})
+}
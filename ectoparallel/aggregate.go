@@ -0,0 +1,251 @@
+package ectoparallel
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Reduce applies an accumulator function over an array in parallel.
+// The array is split into chunks that are each accumulated from initialValue, then the chunk results are merged in order with the combiner.
+// The combiner must be associative and initialValue must be an identity for it (such as 0 for addition), or the result depends on the chunking
+// items: The array to reduce
+// accumulator: The accumulator function to use within a chunk
+// combiner: The function that merges the results of two neighbouring chunks
+// initialValue: The starting value of every chunk
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Reduce[T any, U any](slice []T, accumulator func(U, T) U, combiner func(U, U) U, initialValue U, opts ...Option) U {
+	partials := chunks(slice, newOptions(opts), func(chunk []T) U {
+		result := initialValue
+		for _, item := range chunk {
+			result = accumulator(result, item)
+		}
+		return result
+	})
+	result := initialValue
+	for _, partial := range partials {
+		result = combiner(result, partial)
+	}
+	return result
+}
+
+// Sum returns the sum of all elements in an array, adding chunks in parallel
+// items: The array to sum
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Sum[T int | int32 | int64 | float32 | float64](slice []T, opts ...Option) T {
+	add := func(a T, b T) T {
+		return a + b
+	}
+	return Reduce(slice, add, add, 0, opts...)
+}
+
+// Min returns the minimum value in an array, or zero if it is empty
+// items: The array to get the minimum value from
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Min[T int | int32 | int64 | float32 | float64](slice []T, opts ...Option) T {
+	return extreme(slice, newOptions(opts), func(a T, b T) bool {
+		return a < b
+	})
+}
+
+// Max returns the maximum value in an array, or zero if it is empty
+// items: The array to get the maximum value from
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Max[T int | int32 | int64 | float32 | float64](slice []T, opts ...Option) T {
+	return extreme(slice, newOptions(opts), func(a T, b T) bool {
+		return a > b
+	})
+}
+
+// Count returns the number of elements in an array that satisfy a condition, testing them in parallel
+// items: The array to search
+// predicate: The predicate to test each element against
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Count[T any](slice []T, fn func(T) bool, opts ...Option) int {
+	return Reduce(slice, func(count int, item T) int {
+		if fn(item) {
+			count++
+		}
+		return count
+	}, func(a int, b int) int {
+		return a + b
+	}, 0, opts...)
+}
+
+// GroupBy groups the elements of an array by the key the selector returns, like ectolinq.GroupWhere.
+// Each chunk is grouped into its own map in parallel and the maps are merged in order, so every group keeps the order of the array
+// items: The array to group
+// selector: The selector function that returns the key of an element
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func GroupBy[T any, K comparable](slice []T, selector func(T) K, opts ...Option) map[K][]T {
+	partials := chunks(slice, newOptions(opts), func(chunk []T) map[K][]T {
+		groups := make(map[K][]T)
+		for _, item := range chunk {
+			key := selector(item)
+			groups[key] = append(groups[key], item)
+		}
+		return groups
+	})
+	if len(partials) == 1 {
+		return partials[0]
+	}
+	groups := make(map[K][]T)
+	for _, partial := range partials {
+		for key, items := range partial {
+			groups[key] = append(groups[key], items...)
+		}
+	}
+	return groups
+}
+
+// Distinct returns the distinct elements of an array in first-seen order, like ectolinq.Distinct.
+// Each chunk removes its own duplicates in parallel, then the chunks are merged in order
+// items: The array to search
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Distinct[T comparable](slice []T, opts ...Option) []T {
+	partials := chunks(slice, newOptions(opts), func(chunk []T) []T {
+		seen := make(map[T]struct{}, len(chunk))
+		distinct := make([]T, 0, len(chunk))
+		for _, item := range chunk {
+			if _, ok := seen[item]; !ok {
+				seen[item] = struct{}{}
+				distinct = append(distinct, item)
+			}
+		}
+		return distinct
+	})
+	if len(partials) == 1 {
+		return partials[0]
+	}
+	seen := make(map[T]struct{})
+	distinct := make([]T, 0, len(slice))
+	for _, partial := range partials {
+		for _, item := range partial {
+			if _, ok := seen[item]; !ok {
+				seen[item] = struct{}{}
+				distinct = append(distinct, item)
+			}
+		}
+	}
+	return distinct
+}
+
+// Any determines whether any element of an array satisfies a condition. Every worker stops as soon as one element matches
+// items: The array to search
+// predicate: The predicate to test each element against
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Any[T any](slice []T, fn func(T) bool, opts ...Option) bool {
+	var found atomic.Bool
+	chunks(slice, newOptions(opts), func(chunk []T) struct{} {
+		for _, item := range chunk {
+			if found.Load() {
+				break
+			}
+			if fn(item) {
+				found.Store(true)
+			}
+		}
+		return struct{}{}
+	})
+	return found.Load()
+}
+
+// All determines whether all elements of an array satisfy a condition. Every worker stops as soon as one element fails
+// items: The array to search
+// predicate: The predicate to test each element against
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func All[T any](slice []T, fn func(T) bool, opts ...Option) bool {
+	return !Any(slice, func(item T) bool {
+		return !fn(item)
+	}, opts...)
+}
+
+// Find returns the first element in the array that satisfies the predicate and whether one was found.
+// As in ectolinq.Find, the first match in array order wins. Once a match is found, workers skip the elements after it
+// and stop when they reach it, so only the elements before the match still need to be tested
+// items: The array to search
+// predicate: The predicate to test each element against
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Find[T any](slice []T, fn func(T) bool, opts ...Option) (T, bool) {
+	var first atomic.Int64
+	first.Store(int64(len(slice)))
+	o := newOptions(opts)
+	err := runChunks(context.Background(), len(slice), o, nil, func(_ int, lo int, hi int) {
+		for i := lo; i < hi && int64(i) < first.Load(); i++ {
+			if !fn(slice[i]) {
+				continue
+			}
+			for {
+				current := first.Load()
+				if int64(i) >= current || first.CompareAndSwap(current, int64(i)) {
+					return
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+	if index := first.Load(); index < int64(len(slice)) {
+		return slice[index], true
+	}
+	var zero T
+	return zero, false
+}
+
+// extreme returns the element that beats every other by the given comparison, or zero if the array is empty
+func extreme[T any](slice []T, o options, beats func(T, T) bool) T {
+	var zero T
+	if len(slice) == 0 {
+		return zero
+	}
+	partials := chunks(slice, o, func(chunk []T) T {
+		best := chunk[0]
+		for _, item := range chunk[1:] {
+			if beats(item, best) {
+				best = item
+			}
+		}
+		return best
+	})
+	best := partials[0]
+	for _, partial := range partials[1:] {
+		if beats(partial, best) {
+			best = partial
+		}
+	}
+	return best
+}
+
+// chunks calls fn for each chunk of the array in parallel and returns the results in chunk order.
+// A panic in fn is raised again on the calling goroutine as a *PanicError
+func chunks[T any, R any](slice []T, o options, fn func(chunk []T) R) []R {
+	var results []R
+	err := runChunks(context.Background(), len(slice), o, func(count int) {
+		results = make([]R, count)
+	}, func(c int, lo int, hi int) {
+		results[c] = fn(slice[lo:hi])
+	})
+	if err != nil {
+		panic(err)
+	}
+	return results
+}
+
+// runChunks splits [0, n) into chunks of the grain size and calls fn with the index and bounds of each chunk.
+// If start is not nil, it is called with the number of chunks first.
+// The chunks are scheduled like the items of any other operation, so WorkStealing spreads them across workers as they free up
+func runChunks(ctx context.Context, n int, o options, start func(count int), fn func(c int, lo int, hi int)) error {
+	grain := o.grain(n, o.workers(n))
+	count := (n + grain - 1) / grain
+	if start != nil {
+		start(count)
+	}
+	chunkOptions := o
+	chunkOptions.grainSize = 1
+	chunkOptions.collectErrors = false
+	return run(ctx, count, chunkOptions, func(_ context.Context, _ int, c int) error {
+		lo := c * grain
+		fn(c, lo, min(lo+grain, n))
+		return nil
+	})
+}
@@ -0,0 +1,198 @@
+package ectoparallel
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Gobusters/ectolinq"
+)
+
+// aggregateSchedules are the option sets the aggregation tests run under
+var aggregateSchedules = map[string][]Option{
+	"Default":             nil,
+	"One worker":          {WithConcurrency(1)},
+	"Small grain":         {WithConcurrency(4), WithGrainSize(3)},
+	"Work stealing":       {WithConcurrency(4), WorkStealing()},
+	"More workers than n": {WithConcurrency(100000)},
+}
+
+func TestAggregatesMatchSequential(t *testing.T) {
+	numbers := make([]int, 5003)
+	for i := range numbers {
+		numbers[i] = (i*7919)%1000 - 500
+	}
+	isEven := func(n int) bool { return n%2 == 0 }
+	key := func(n int) int { return n % 7 }
+
+	for name, opts := range aggregateSchedules {
+		for _, items := range [][]int{numbers, numbers[:1], {}} {
+			t.Run(fmt.Sprintf("%s/%d items", name, len(items)), func(t *testing.T) {
+				if expected, got := ectolinq.Sum(items), Sum(items, opts...); got != expected {
+					t.Errorf("Sum: expected %d, got %d", expected, got)
+				}
+				if expected, got := ectolinq.Min(items), Min(items, opts...); got != expected {
+					t.Errorf("Min: expected %d, got %d", expected, got)
+				}
+				if expected, got := ectolinq.Max(items), Max(items, opts...); got != expected {
+					t.Errorf("Max: expected %d, got %d", expected, got)
+				}
+				if expected, got := ectolinq.Count(items, isEven), Count(items, isEven, opts...); got != expected {
+					t.Errorf("Count: expected %d, got %d", expected, got)
+				}
+				if expected, got := ectolinq.Distinct(items), Distinct(items, opts...); !equalSlices(got, expected) {
+					t.Errorf("Distinct differs from ectolinq.Distinct")
+				}
+				if expected, got := ectolinq.GroupWhere(items, key), GroupBy(items, key, opts...); !maps.EqualFunc(got, expected, equalSlices) {
+					t.Errorf("GroupBy differs from ectolinq.GroupWhere")
+				}
+				for _, target := range []int{-500, 0, 499, 1000} {
+					matches := func(n int) bool { return n == target }
+					if expected, got := ectolinq.Any(items, matches), Any(items, matches, opts...); got != expected {
+						t.Errorf("Any(%d): expected %v, got %v", target, expected, got)
+					}
+					notTarget := func(n int) bool { return n != target }
+					if expected, got := ectolinq.All(items, notTarget), All(items, notTarget, opts...); got != expected {
+						t.Errorf("All(%d): expected %v, got %v", target, expected, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	words := strings.Fields("the quick brown fox jumps over the lazy dog")
+	concat := func(a string, b string) string { return a + b }
+	for name, opts := range aggregateSchedules {
+		t.Run(name, func(t *testing.T) {
+			joined := Reduce(words, concat, concat, "", opts...)
+			if expected := strings.Join(words, ""); joined != expected {
+				t.Errorf("Expected chunks to be combined in order: expected %q, got %q", expected, joined)
+			}
+			lengths := Reduce(words, func(total int, word string) int { return total + len(word) }, func(a int, b int) int { return a + b }, 0, opts...)
+			if lengths != 35 {
+				t.Errorf("Expected 35, got %d", lengths)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	numbers := make([]int, 10000)
+	for i := range numbers {
+		numbers[i] = i % 100
+	}
+
+	for name, opts := range aggregateSchedules {
+		t.Run(name, func(t *testing.T) {
+			found, ok := Find(numbers, func(n int) bool {
+				return n == 42
+			}, opts...)
+			if !ok || found != 42 {
+				t.Errorf("Expected to find 42, got %d (%v)", found, ok)
+			}
+			_, ok = Find(numbers, func(n int) bool { return n < 0 }, opts...)
+			if ok {
+				t.Errorf("Expected no match")
+			}
+		})
+	}
+
+	t.Run("Returns the first match in order", func(t *testing.T) {
+		type item struct{ index, value int }
+		items := make([]item, 10000)
+		for i := range items {
+			items[i] = item{i, i % 100}
+		}
+		for name, opts := range aggregateSchedules {
+			found, _ := Find(items, func(it item) bool { return it.value == 42 }, opts...)
+			if found.index != 42 {
+				t.Errorf("%s: expected the match at index 42, got %d", name, found.index)
+			}
+		}
+	})
+
+	t.Run("Stops once the answer is known", func(t *testing.T) {
+		var calls atomic.Int64
+		Find(numbers, func(n int) bool {
+			calls.Add(1)
+			return n == 5
+		}, WithConcurrency(1))
+		if calls.Load() != 6 {
+			t.Errorf("Expected 6 predicate calls, got %d", calls.Load())
+		}
+	})
+}
+
+func TestAnyStopsEarly(t *testing.T) {
+	numbers := make([]int, 100000)
+	var calls atomic.Int64
+	Any(numbers, func(int) bool {
+		calls.Add(1)
+		return true
+	}, WithConcurrency(4))
+	if calls.Load() > 4 {
+		t.Errorf("Expected each worker to stop after the first match, got %d calls", calls.Load())
+	}
+
+	calls.Store(0)
+	All(numbers, func(int) bool {
+		calls.Add(1)
+		return false
+	}, WithConcurrency(4))
+	if calls.Load() > 4 {
+		t.Errorf("Expected each worker to stop after the first failure, got %d calls", calls.Load())
+	}
+}
+
+func TestAggregatePanic(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*PanicError); !ok {
+			t.Errorf("Expected GroupBy to panic with a PanicError on the calling goroutine")
+		}
+	}()
+	GroupBy([]int{1, 2, 3}, func(n int) int {
+		if n == 3 {
+			panic("boom")
+		}
+		return n
+	})
+}
+
+func BenchmarkSum(b *testing.B) {
+	numbers := make([]float64, 1<<20)
+	for i := range numbers {
+		numbers[i] = float64(i)
+	}
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			ectolinq.Sum(numbers)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			Sum(numbers)
+		}
+	})
+}
+
+func BenchmarkGroupBy(b *testing.B) {
+	numbers := make([]int, 1<<18)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	key := func(n int) int { return n % 64 }
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			ectolinq.GroupWhere(numbers, key)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			GroupBy(numbers, key)
+		}
+	})
+}
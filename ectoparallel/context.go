@@ -0,0 +1,44 @@
+package ectoparallel
+
+import "context"
+
+// ForEachCtx executes an action for each element in the array in parallel, stopping at the first error.
+// A callback that panics fails with a PanicError instead of crashing the process
+// ctx: The context to pass to each action. Cancelling it stops the remaining work
+// slice: The array to iterate
+// fn: The action to perform on each element
+// opts: Options such as WithConcurrency and CollectErrors
+func ForEachCtx[T any](ctx context.Context, slice []T, fn func(context.Context, T) error, opts ...Option) error {
+	return run(ctx, len(slice), newOptions(opts), func(ctx context.Context, _ int, i int) error {
+		return fn(ctx, slice[i])
+	})
+}
+
+// MapCtx projects each element of an array into a new form in parallel, stopping at the first error.
+// The results are in the same order as the array. If any callback fails, the result is nil
+// ctx: The context to pass to each selector. Cancelling it stops the remaining work
+// slice: The array to map
+// fn: The selector function to use
+// opts: Options such as WithConcurrency and CollectErrors
+func MapCtx[T any, U any](ctx context.Context, slice []T, fn func(context.Context, T) (U, error), opts ...Option) ([]U, error) {
+	mapped := make([]U, len(slice))
+	err := run(ctx, len(slice), newOptions(opts), func(ctx context.Context, _ int, i int) error {
+		value, err := fn(ctx, slice[i])
+		mapped[i] = value
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapped, nil
+}
+
+// FilterCtx returns the elements of an array that satisfy the predicate, testing them in parallel and stopping at the first error.
+// The results are in the same order as the array unless the Unordered option is given. If any predicate fails, the result is nil
+// ctx: The context to pass to each predicate. Cancelling it stops the remaining work
+// slice: The array to filter
+// fn: The predicate to test each element against
+// opts: Options such as WithConcurrency, CollectErrors and Unordered
+func FilterCtx[T any](ctx context.Context, slice []T, fn func(context.Context, T) (bool, error), opts ...Option) ([]T, error) {
+	return filter(ctx, slice, newOptions(opts), fn)
+}
@@ -0,0 +1,218 @@
+package ectoparallel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachCtx(t *testing.T) {
+	numbers := make([]int, 100)
+	for i := range numbers {
+		numbers[i] = i
+	}
+
+	t.Run("Runs every callback", func(t *testing.T) {
+		var sum atomic.Int64
+		err := ForEachCtx(context.Background(), numbers, func(_ context.Context, n int) error {
+			sum.Add(int64(n))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if sum.Load() != 4950 {
+			t.Errorf("Expected sum to be 4950, got %d", sum.Load())
+		}
+	})
+
+	t.Run("Stops at the first error", func(t *testing.T) {
+		failure := errors.New("failure")
+		var calls atomic.Int64
+		err := ForEachCtx(context.Background(), numbers, func(_ context.Context, n int) error {
+			calls.Add(1)
+			if n == 3 {
+				return failure
+			}
+			return nil
+		}, WithConcurrency(1))
+		if !errors.Is(err, failure) {
+			t.Errorf("Expected %v, got %v", failure, err)
+		}
+		if calls.Load() != 4 {
+			t.Errorf("Expected the work to stop after the failing callback, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("The first error cancels the context of running callbacks", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := ForEachCtx(context.Background(), []int{0, 1}, func(ctx context.Context, n int) error {
+			if n == 0 {
+				return failure
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return errors.New("not cancelled")
+			}
+		}, WithConcurrency(2))
+		if err != failure {
+			t.Errorf("Expected only the first error, got %v", err)
+		}
+	})
+
+	t.Run("CollectErrors joins every error", func(t *testing.T) {
+		var calls atomic.Int64
+		err := ForEachCtx(context.Background(), numbers, func(_ context.Context, n int) error {
+			calls.Add(1)
+			if n%10 == 0 {
+				return errors.New("failure")
+			}
+			return nil
+		}, CollectErrors())
+		if calls.Load() != 100 {
+			t.Errorf("Expected every callback to run, got %d calls", calls.Load())
+		}
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(joined.Unwrap()) != 10 {
+			t.Errorf("Expected 10 joined errors, got %v", err)
+		}
+	})
+
+	t.Run("A cancelled context stops the work", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var calls atomic.Int64
+		err := ForEachCtx(ctx, numbers, func(context.Context, int) error {
+			calls.Add(1)
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected %v, got %v", context.Canceled, err)
+		}
+		if calls.Load() != 0 {
+			t.Errorf("Expected no callbacks to run, got %d", calls.Load())
+		}
+	})
+
+	t.Run("Cancelling the context during the run returns its error", func(t *testing.T) {
+		for name, opts := range map[string][]Option{"default": nil, "CollectErrors": {CollectErrors()}} {
+			ctx, cancel := context.WithCancel(context.Background())
+			err := ForEachCtx(ctx, numbers, func(_ context.Context, n int) error {
+				if n == 0 {
+					cancel()
+				}
+				return nil
+			}, append(opts, WithConcurrency(1))...)
+			if err != context.Canceled {
+				t.Errorf("%s: expected %v itself, got %#v", name, context.Canceled, err)
+			}
+		}
+	})
+
+	t.Run("A panic becomes a PanicError", func(t *testing.T) {
+		err := ForEachCtx(context.Background(), numbers, func(_ context.Context, n int) error {
+			if n == 42 {
+				panic("boom")
+			}
+			return nil
+		})
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("Expected a PanicError, got %v", err)
+		}
+		if panicErr.Value != "boom" {
+			t.Errorf("Expected the panic value boom, got %v", panicErr.Value)
+		}
+		if !strings.Contains(err.Error(), "context_test.go") {
+			t.Errorf("Expected the error to include the stack trace, got %v", err)
+		}
+	})
+
+	t.Run("A panic with an error unwraps to it", func(t *testing.T) {
+		err := ForEachCtx(context.Background(), []int{1}, func(context.Context, int) error {
+			panic(context.DeadlineExceeded)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestWithConcurrency(t *testing.T) {
+	numbers := make([]int, 50)
+	var running, peak atomic.Int64
+	err := ForEachCtx(context.Background(), numbers, func(context.Context, int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return nil
+	}, WithConcurrency(3))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent callbacks, got %d", peak.Load())
+	}
+}
+
+func TestMapCtx(t *testing.T) {
+	numbers := []int{1, 2, 3, 4, 5}
+
+	t.Run("Maps in order", func(t *testing.T) {
+		squared, err := MapCtx(context.Background(), numbers, func(_ context.Context, n int) (int, error) {
+			return n * n, nil
+		})
+		expected := []int{1, 4, 9, 16, 25}
+		if err != nil || !equalSlices(squared, expected) {
+			t.Errorf("Expected %v, got %v (%v)", expected, squared, err)
+		}
+	})
+
+	t.Run("Returns nil on error", func(t *testing.T) {
+		failure := errors.New("failure")
+		mapped, err := MapCtx(context.Background(), numbers, func(_ context.Context, n int) (int, error) {
+			if n == 5 {
+				return 0, failure
+			}
+			return n, nil
+		})
+		if !errors.Is(err, failure) || mapped != nil {
+			t.Errorf("Expected nil and %v, got %v and %v", failure, mapped, err)
+		}
+	})
+}
+
+func TestFilterCtx(t *testing.T) {
+	numbers := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	t.Run("Filters in order", func(t *testing.T) {
+		evens, err := FilterCtx(context.Background(), numbers, func(_ context.Context, n int) (bool, error) {
+			return n%2 == 0, nil
+		}, WithConcurrency(4))
+		expected := []int{2, 4, 6, 8, 10}
+		if err != nil || !equalSlices(evens, expected) {
+			t.Errorf("Expected %v, got %v (%v)", expected, evens, err)
+		}
+	})
+
+	t.Run("Returns nil on error", func(t *testing.T) {
+		failure := errors.New("failure")
+		filtered, err := FilterCtx(context.Background(), numbers, func(context.Context, int) (bool, error) {
+			return true, failure
+		})
+		if !errors.Is(err, failure) || filtered != nil {
+			t.Errorf("Expected nil and %v, got %v and %v", failure, filtered, err)
+		}
+	})
+}
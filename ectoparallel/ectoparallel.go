@@ -1,77 +1,92 @@
 package ectoparallel
 
-import (
-	"runtime"
-	"sync"
-)
+import "context"
 
-// ForEach executes an action for each element in the array in parallel
+// ForEach executes an action for each element in the array in parallel.
+// If the action panics, ForEach panics on the calling goroutine with a *PanicError
 // items: The array to iterate
 // action: The action to perform on each element
-func ForEach[T any](slice []T, fn func(T)) {
-	var wg sync.WaitGroup
-	numWorkers := runtime.GOMAXPROCS(0)
-
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(start int) {
-			defer wg.Done()
-			for j := start; j < len(slice); j += numWorkers {
-				fn(slice[j])
-			}
-		}(i)
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func ForEach[T any](slice []T, fn func(T), opts ...Option) {
+	err := run(context.Background(), len(slice), newOptions(opts), func(_ context.Context, _ int, i int) error {
+		fn(slice[i])
+		return nil
+	})
+	if err != nil {
+		panic(err)
 	}
-
-	wg.Wait()
 }
 
-// Map projects each element of an array into a new form in parallel
+// Map projects each element of an array into a new form in parallel.
+// If the selector panics, Map panics on the calling goroutine with a *PanicError
 // items: The array to map
 // selector: The selector function to use
-func Map[T any, U any](slice []T, fn func(T) U) []U {
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Map[T any, U any](slice []T, fn func(T) U, opts ...Option) []U {
 	mapped := make([]U, len(slice))
-	var wg sync.WaitGroup
-	numWorkers := runtime.GOMAXPROCS(0)
-
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(start int) {
-			defer wg.Done()
-			for j := start; j < len(slice); j += numWorkers {
-				mapped[j] = fn(slice[j])
-			}
-		}(i)
+	err := run(context.Background(), len(slice), newOptions(opts), func(_ context.Context, _ int, i int) error {
+		mapped[i] = fn(slice[i])
+		return nil
+	})
+	if err != nil {
+		panic(err)
 	}
-
-	wg.Wait()
 	return mapped
 }
 
-// Filter removes all elements from an array that satisfy the predicate in parallel
+// Filter removes all elements from an array that satisfy the predicate in parallel.
+// The results are in the same order as the array unless the Unordered option is given.
+// If the predicate panics, Filter panics on the calling goroutine with a *PanicError
 // items: The array to filter
 // predicate: The predicate to test each element against
-func Filter[T any](slice []T, fn func(T) bool) []T {
-	filtered := make([]T, 0, len(slice))
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	numWorkers := runtime.GOMAXPROCS(0)
+// opts: Options such as WithConcurrency, Unordered, WithGrainSize and WorkStealing
+func Filter[T any](slice []T, fn func(T) bool, opts ...Option) []T {
+	filtered, err := filter(context.Background(), slice, newOptions(opts), func(_ context.Context, item T) (bool, error) {
+		return fn(item), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return filtered
+}
 
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(start int) {
-			defer wg.Done()
-			localFiltered := make([]T, 0)
-			for j := start; j < len(slice); j += numWorkers {
-				if fn(slice[j]) {
-					localFiltered = append(localFiltered, slice[j])
-				}
+// filter tests each element of the slice in parallel and returns those the predicate keeps.
+// In order, it records a flag per element and collects the kept elements afterwards.
+// Unordered, each worker appends to its own slice and the slices are concatenated
+func filter[T any](ctx context.Context, slice []T, o options, fn func(context.Context, T) (bool, error)) ([]T, error) {
+	if o.unordered {
+		locals := make([][]T, o.workers(len(slice)))
+		err := run(ctx, len(slice), o, func(ctx context.Context, worker int, i int) error {
+			ok, err := fn(ctx, slice[i])
+			if ok && err == nil {
+				locals[worker] = append(locals[worker], slice[i])
 			}
-			mu.Lock()
-			filtered = append(filtered, localFiltered...)
-			mu.Unlock()
-		}(i)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		filtered := make([]T, 0, len(slice))
+		for _, local := range locals {
+			filtered = append(filtered, local...)
+		}
+		return filtered, nil
 	}
 
-	wg.Wait()
-	return filtered
+	keep := make([]bool, len(slice))
+	err := run(ctx, len(slice), o, func(ctx context.Context, _ int, i int) error {
+		ok, err := fn(ctx, slice[i])
+		keep[i] = ok
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]T, 0, len(slice))
+	for i, item := range slice {
+		if keep[i] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
 }
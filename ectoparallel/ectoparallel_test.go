@@ -1,9 +1,14 @@
 package ectoparallel
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"testing"
+
+	"github.com/Gobusters/ectolinq"
 )
 
 func TestForEach(t *testing.T) {
@@ -41,12 +46,93 @@ func TestFilter(t *testing.T) {
 	})
 
 	expected := []int{2, 4, 6, 8, 10}
-	sort.Ints(evens) // Sort because Filter doesn't guarantee order
 	if !equalSlices(evens, expected) {
 		t.Errorf("Expected %v, got %v", expected, evens)
 	}
 }
 
+func TestFilterUnordered(t *testing.T) {
+	numbers := make([]int, 1000)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	evens := Filter(numbers, func(n int) bool {
+		return n%2 == 0
+	}, Unordered())
+
+	expected := ectolinq.Filter(numbers, func(n int) bool {
+		return n%2 == 0
+	})
+	sort.Ints(evens) // Sort because Unordered doesn't guarantee order
+	if !equalSlices(evens, expected) {
+		t.Errorf("Expected %v, got %v", expected, evens)
+	}
+}
+
+func TestFilterPanic(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*PanicError); !ok {
+			t.Errorf("Expected Filter to panic with a PanicError on the calling goroutine")
+		}
+	}()
+	Filter([]int{1, 2, 3}, func(n int) bool {
+		if n == 2 {
+			panic("boom")
+		}
+		return true
+	})
+}
+
+// TestMatchesSequential checks that the parallel operators return the same results, in the same order, as the sequential functions
+func TestMatchesSequential(t *testing.T) {
+	numbers := make([]int, 10007)
+	for i := range numbers {
+		numbers[i] = (i * 7919) % 1000
+	}
+	isSmall := func(n int) bool { return n < 300 }
+	double := func(n int) int { return n * 2 }
+
+	schedules := map[string][]Option{
+		"Small grain":   {WithConcurrency(4), WithGrainSize(7)},
+		"Work stealing": {WithConcurrency(4), WorkStealing()},
+		"Unordered":     {WithConcurrency(4), Unordered()},
+	}
+	for _, concurrency := range []int{1, 2, 3, 8, 64} {
+		schedules[fmt.Sprint("Concurrency ", concurrency)] = []Option{WithConcurrency(concurrency)}
+	}
+
+	for name, opts := range schedules {
+		t.Run(name, func(t *testing.T) {
+			// Unordered only lets Filter return its results in any order, so compare those sorted
+			sameFilter := equalSlices[int]
+			if newOptions(opts).unordered {
+				sameFilter = func(a []int, b []int) bool {
+					return equalSlices(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+				}
+			}
+			if expected, got := ectolinq.Filter(numbers, isSmall), Filter(numbers, isSmall, opts...); !sameFilter(got, expected) {
+				t.Errorf("Filter differs from ectolinq.Filter")
+			}
+			if expected, got := ectolinq.Map(numbers, double), Map(numbers, double, opts...); !equalSlices(got, expected) {
+				t.Errorf("Map differs from ectolinq.Map")
+			}
+
+			filtered, err := FilterCtx(context.Background(), numbers, func(_ context.Context, n int) (bool, error) {
+				return isSmall(n), nil
+			}, opts...)
+			if expected := ectolinq.Filter(numbers, isSmall); err != nil || !sameFilter(filtered, expected) {
+				t.Errorf("FilterCtx differs from ectolinq.Filter (%v)", err)
+			}
+			mapped, err := MapCtx(context.Background(), numbers, func(_ context.Context, n int) (int, error) {
+				return double(n), nil
+			}, opts...)
+			if expected := ectolinq.Map(numbers, double); err != nil || !equalSlices(mapped, expected) {
+				t.Errorf("MapCtx differs from ectolinq.Map (%v)", err)
+			}
+		})
+	}
+}
+
 func TestParallelExecution(t *testing.T) {
 	numbers := make([]int, 1000)
 	for i := range numbers {
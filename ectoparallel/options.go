@@ -0,0 +1,87 @@
+package ectoparallel
+
+import "runtime"
+
+// Option configures how a parallel operation runs
+type Option func(*options)
+
+type options struct {
+	concurrency   int
+	collectErrors bool
+	unordered     bool
+	grainSize     int
+	workStealing  bool
+}
+
+// WithConcurrency limits the number of goroutines an operation runs its callbacks on.
+// Zero or less uses GOMAXPROCS, which is also the default
+// n: The maximum number of concurrent callbacks
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// CollectErrors makes an operation run every callback even after one fails, and return all of their errors joined with errors.Join.
+// By default the first error cancels the remaining work and is the only error returned
+func CollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// Unordered lets an operation return its results in any order, which saves a pass over the input.
+// By default results are in the same order as the input, matching the sequential functions
+func Unordered() Option {
+	return func(o *options) {
+		o.unordered = true
+	}
+}
+
+// WithGrainSize sets the number of consecutive items a worker runs before moving on to the next chunk.
+// Each worker runs contiguous chunks, so neighbouring results are written by the same goroutine.
+// By default the input is split into one chunk per worker, or into small chunks when WorkStealing is used.
+// Smaller chunks balance uneven work better but cost more scheduling
+// n: The number of items per chunk
+func WithGrainSize(n int) Option {
+	return func(o *options) {
+		o.grainSize = n
+	}
+}
+
+// WorkStealing schedules items dynamically. Each worker starts with an even share of the input,
+// and a worker that runs out steals half of the work another worker has left. Use it when some items take much longer than others
+func WorkStealing() Option {
+	return func(o *options) {
+		o.workStealing = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// workers returns the number of goroutines to use for n items
+func (o options) workers(n int) int {
+	workers := o.concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return max(min(workers, n), 1)
+}
+
+// grain returns the chunk size to use for n items on the given number of workers
+func (o options) grain(n int, workers int) int {
+	switch {
+	case o.grainSize > 0:
+		return o.grainSize
+	case o.workStealing:
+		return max(n/(workers*32), 1)
+	default:
+		return max((n+workers-1)/workers, 1)
+	}
+}
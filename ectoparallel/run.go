@@ -0,0 +1,198 @@
+package ectoparallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// PanicError is returned when a callback panics. It holds the panic value and the stack of the goroutine that panicked
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error returns the panic value followed by the stack trace
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("ectoparallel: panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is and errors.As can see through the panic
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// run calls fn for each index in [0, n) on the workers allowed by the options, and returns the error to report.
+// fn is also given the number of the worker running it, from zero up to options.workers(n), for keeping per-worker results.
+// Unless errors are collected, the first error cancels the context passed to fn and stops the workers from starting more indices.
+// Cancelling the parent context always stops them, and its error is returned unwrapped if no callback failed
+func run(parent context.Context, n int, o options, fn func(ctx context.Context, worker int, i int) error) error {
+	if n == 0 || parent.Err() != nil {
+		return parent.Err()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	r := &runner{ctx: ctx, cancel: cancel, fn: fn, collectErrors: o.collectErrors}
+	// Checking an atomic flag per index is much cheaper than calling ctx.Err, which takes a lock shared by every worker
+	stop := context.AfterFunc(ctx, func() {
+		r.stopped.Store(true)
+	})
+	defer stop()
+
+	workers := o.workers(n)
+	grain := o.grain(n, workers)
+	var wg sync.WaitGroup
+	if o.workStealing {
+		spans := splitSpans(n, workers)
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.steal(spans, w, grain)
+			}()
+		}
+	} else {
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for lo := w * grain; lo < n && !r.stopped.Load(); lo += workers * grain {
+					r.runRange(w, lo, min(lo+grain, n))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if len(r.errs) == 0 {
+		return parent.Err()
+	}
+	if !o.collectErrors {
+		return r.errs[0]
+	}
+	if err := parent.Err(); err != nil {
+		r.errs = append(r.errs, err)
+	}
+	return errors.Join(r.errs...)
+}
+
+// runner holds the state shared by the workers of a run
+type runner struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	fn            func(ctx context.Context, worker int, i int) error
+	collectErrors bool
+	stopped       atomic.Bool
+	mutex         sync.Mutex
+	errs          []error
+}
+
+func (r *runner) fail(err error) {
+	r.mutex.Lock()
+	r.errs = append(r.errs, err)
+	r.mutex.Unlock()
+	if !r.collectErrors {
+		r.stopped.Store(true)
+		r.cancel()
+	}
+}
+
+// runRange calls fn for each index in [lo, hi) until the run is stopped
+func (r *runner) runRange(worker int, lo int, hi int) {
+	for lo < hi && !r.stopped.Load() {
+		lo = r.runUntilPanic(worker, lo, hi)
+	}
+}
+
+// runUntilPanic calls fn for each index in [lo, hi) until the run is stopped or fn panics.
+// The panic is reported as a PanicError and the index to continue from is returned, so only one recover is deferred per range
+func (r *runner) runUntilPanic(worker int, lo int, hi int) (next int) {
+	i := lo
+	defer func() {
+		if v := recover(); v != nil {
+			r.fail(&PanicError{Value: v, Stack: debug.Stack()})
+			next = i + 1
+		}
+	}()
+	for ; i < hi && !r.stopped.Load(); i++ {
+		if err := r.fn(r.ctx, worker, i); err != nil {
+			r.fail(err)
+		}
+	}
+	return hi
+}
+
+// steal runs a work-stealing worker. It takes grain indices at a time from the front of its own span,
+// and once that is empty steals the back half of another worker's span, until there is nothing left to steal
+func (r *runner) steal(spans []span, worker int, grain int) {
+	own := &spans[worker]
+	for !r.stopped.Load() {
+		if lo, hi, ok := own.take(grain); ok {
+			r.runRange(worker, lo, hi)
+			continue
+		}
+		stolen := false
+		for k := 1; k < len(spans) && !stolen; k++ {
+			if lo, hi, ok := spans[(worker+k)%len(spans)].split(); ok {
+				own.set(lo, hi)
+				stolen = true
+			}
+		}
+		if !stolen {
+			return
+		}
+	}
+}
+
+// span is the range of indices [lo, hi) a work-stealing worker has left to run.
+// It is padded to its own cache line so that workers taking from their own spans don't slow each other down
+type span struct {
+	mutex sync.Mutex
+	lo    int
+	hi    int
+	_     [40]byte
+}
+
+// splitSpans divides [0, n) into one contiguous span per worker
+func splitSpans(n int, workers int) []span {
+	spans := make([]span, workers)
+	for w := range spans {
+		spans[w].lo = w * n / workers
+		spans[w].hi = (w + 1) * n / workers
+	}
+	return spans
+}
+
+// take removes up to grain indices from the front of the span
+func (s *span) take(grain int) (int, int, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.lo >= s.hi {
+		return 0, 0, false
+	}
+	lo := s.lo
+	s.lo = min(lo+grain, s.hi)
+	return lo, s.lo, true
+}
+
+// split removes the back half of the span, or its last index if only one is left
+func (s *span) split() (int, int, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.lo >= s.hi {
+		return 0, 0, false
+	}
+	hi := s.hi
+	s.hi = s.lo + (s.hi-s.lo)/2
+	return s.hi, hi, true
+}
+
+func (s *span) set(lo int, hi int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lo, s.hi = lo, hi
+}
@@ -0,0 +1,172 @@
+package ectoparallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunSchedules(t *testing.T) {
+	schedules := map[string][]Option{
+		"Static":               nil,
+		"Static grain 7":       {WithGrainSize(7)},
+		"Work stealing":        {WorkStealing()},
+		"Work stealing grain1": {WorkStealing(), WithGrainSize(1)},
+	}
+	for name, opts := range schedules {
+		for _, concurrency := range []int{1, 3, 8, 200} {
+			for _, n := range []int{1, 10, 1000} {
+				t.Run(fmt.Sprintf("%s/%d workers/%d items", name, concurrency, n), func(t *testing.T) {
+					counts := make([]atomic.Int32, n)
+					o := newOptions(append([]Option{WithConcurrency(concurrency)}, opts...))
+					err := run(context.Background(), n, o, func(_ context.Context, worker int, i int) error {
+						if worker < 0 || worker >= o.workers(n) {
+							t.Errorf("Worker %d is out of range", worker)
+						}
+						counts[i].Add(1)
+						return nil
+					})
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					for i := range counts {
+						if counts[i].Load() != 1 {
+							t.Fatalf("Expected index %d to run once, ran %d times", i, counts[i].Load())
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRunContiguousChunks(t *testing.T) {
+	workers := make([]int, 1000)
+	err := run(context.Background(), len(workers), newOptions([]Option{WithConcurrency(4)}), func(_ context.Context, worker int, i int) error {
+		workers[i] = worker
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i := range workers {
+		if expected := i / 250; workers[i] != expected {
+			t.Fatalf("Expected index %d to run on worker %d, got %d", i, expected, workers[i])
+		}
+	}
+}
+
+func TestWorkStealingBalancesSkewedWork(t *testing.T) {
+	// The first item blocks until every other item has run. Without stealing, the rest of the first worker's share
+	// would wait behind it forever
+	n := 100
+	var others sync.WaitGroup
+	others.Add(n - 1)
+	done := make(chan struct{})
+	go func() {
+		others.Wait()
+		close(done)
+	}()
+
+	err := run(context.Background(), n, newOptions([]Option{WithConcurrency(4), WorkStealing(), WithGrainSize(1)}), func(_ context.Context, _ int, i int) error {
+		if i > 0 {
+			others.Done()
+			return nil
+		}
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("the first worker's share was not stolen")
+		}
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunPanicContinuesWithCollectErrors(t *testing.T) {
+	var ran atomic.Int32
+	err := ForEachCtx(context.Background(), make([]int, 100), func(context.Context, int) error {
+		if ran.Add(1)%10 == 0 {
+			panic("boom")
+		}
+		return nil
+	}, CollectErrors(), WithConcurrency(2))
+	if ran.Load() != 100 {
+		t.Errorf("Expected every item to run after a panic, got %d", ran.Load())
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 10 {
+		t.Errorf("Expected 10 panics, got %v", err)
+	}
+}
+
+// stridedMap is the original interleaved scheduling, kept as a benchmark baseline
+func stridedMap[T any, U any](slice []T, fn func(T) U) []U {
+	mapped := make([]U, len(slice))
+	var wg sync.WaitGroup
+	numWorkers := runtime.GOMAXPROCS(0)
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			for j := start; j < len(slice); j += numWorkers {
+				mapped[j] = fn(slice[j])
+			}
+		}(i)
+	}
+	wg.Wait()
+	return mapped
+}
+
+// spin does an amount of work proportional to n that the compiler cannot remove
+func spin(n int) int {
+	x := n
+	for i := 0; i < n; i++ {
+		x = x*31 + i
+	}
+	return x
+}
+
+func BenchmarkMap(b *testing.B) {
+	const size = 1 << 16
+	uniform := make([]int, size)
+	skewed := make([]int, size)
+	for i := range size {
+		uniform[i] = 1
+		// The last eighth of the input does nearly all of the work, so a static split leaves most workers idle
+		if i >= size-size/8 {
+			skewed[i] = 400
+		}
+	}
+	workloads := []struct {
+		name  string
+		items []int
+	}{
+		{"uniform", uniform},
+		{"skewed", skewed},
+	}
+	schedules := []struct {
+		name string
+		fn   func([]int) []int
+	}{
+		{"strided", func(items []int) []int { return stridedMap(items, spin) }},
+		{"chunked", func(items []int) []int { return Map(items, spin) }},
+		{"work-stealing", func(items []int) []int { return Map(items, spin, WorkStealing()) }},
+	}
+	for _, workload := range workloads {
+		for _, schedule := range schedules {
+			b.Run(workload.name+"/"+schedule.name, func(b *testing.B) {
+				for b.Loop() {
+					schedule.fn(workload.items)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ectoparallel
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Gobusters/ectolinq"
+)
+
+// sortThreshold is the length below which sorts run sequentially, because starting workers and merging costs more than it saves
+const sortThreshold = 1 << 13
+
+// Sort sorts the elements of an array in place in ascending order using a parallel merge sort, and returns the array.
+// Arrays shorter than a few thousand elements are sorted sequentially
+// items: The array to sort
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func Sort[T cmp.Ordered](slice []T, opts ...Option) []T {
+	return mergeSort(slice, newOptions(opts), cmp.Less[T], slices.Sort[[]T])
+}
+
+// SortFunc sorts the elements of an array in place using the specified comparer function and a parallel merge sort, and returns the array.
+// Like ectolinq.SortWhere, the sort is not stable. If the comparer panics, SortFunc panics on the calling goroutine
+// items: The array to sort
+// comparer: The function that reports whether a sorts before b
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func SortFunc[T any](slice []T, less func(a T, b T) bool, opts ...Option) []T {
+	return mergeSort(slice, newOptions(opts), less, func(run []T) {
+		sort.Slice(run, func(i, j int) bool {
+			return less(run[i], run[j])
+		})
+	})
+}
+
+// SortStable sorts the elements of an array in place by a sort key using a parallel merge sort, and returns the array.
+// Elements with equal keys keep their order. Build the key with ectolinq.By and combine several with ectolinq.CompareKeys,
+// as for OrderBy and ThenBy. If the key panics, SortStable panics on the calling goroutine
+// items: The array to sort
+// key: The key to sort by
+// opts: Options such as WithConcurrency, WithGrainSize and WorkStealing
+func SortStable[T any](slice []T, key ectolinq.SortKey[T], opts ...Option) []T {
+	return mergeSort(slice, newOptions(opts), func(a T, b T) bool {
+		return key(a, b) < 0
+	}, func(run []T) {
+		slices.SortStableFunc(run, key)
+	})
+}
+
+// mergeSort sorts the chunks of the slice in parallel with sortRun, then merges neighbouring runs in rounds until one is left.
+// Each round merges into a buffer and the next merges back, and large merges are split so every worker has a share of the work.
+// The merges keep elements of the left run ahead of equal elements of the right run, so the sort is stable if sortRun is
+func mergeSort[T any](slice []T, o options, less func(a T, b T) bool, sortRun func([]T)) []T {
+	n := len(slice)
+	workers := o.workers(n)
+	if n < sortThreshold || workers == 1 {
+		sortRun(slice)
+		return slice
+	}
+
+	var bounds []int
+	err := runChunks(context.Background(), n, o, func(count int) {
+		bounds = make([]int, count+1)
+		bounds[count] = n
+	}, func(c int, lo int, hi int) {
+		bounds[c] = lo
+		sortRun(slice[lo:hi])
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	mergeOptions := o
+	mergeOptions.grainSize = 1
+	mergeOptions.collectErrors = false
+	src, dst := slice, make([]T, n)
+	for len(bounds) > 2 {
+		pairs := len(bounds) / 2
+		parts := max(workers/pairs, 1)
+		var tasks []mergeTask[T]
+		next := []int{0}
+		for r := 0; r+1 < len(bounds); r += 2 {
+			lo, mid, hi := bounds[r], bounds[r+1], bounds[r+1]
+			if r+2 < len(bounds) {
+				hi = bounds[r+2]
+			}
+			tasks = splitMerge(tasks, src[lo:mid], src[mid:hi], dst[lo:hi], parts, less)
+			next = append(next, hi)
+		}
+		err := run(context.Background(), len(tasks), mergeOptions, func(_ context.Context, _ int, i int) error {
+			tasks[i].merge(less)
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		bounds = next
+		src, dst = dst, src
+	}
+	if &src[0] != &slice[0] {
+		copy(slice, src)
+	}
+	return slice
+}
+
+// mergeTask merges two sorted runs into dst, which is exactly as long as both runs together
+type mergeTask[T any] struct {
+	a   []T
+	b   []T
+	dst []T
+}
+
+// splitMerge appends tasks that merge the sorted runs a and b into dst, split into up to parts independent pieces.
+// The larger run is cut evenly and the other is cut where the same pivots fall, so the pieces can run in parallel
+func splitMerge[T any](tasks []mergeTask[T], a []T, b []T, dst []T, parts int, less func(a T, b T) bool) []mergeTask[T] {
+	parts = min(parts, len(a)+len(b))
+	i0, j0 := 0, 0
+	for k := 1; k <= parts; k++ {
+		i, j := len(a), len(b)
+		switch {
+		case k == parts:
+		case len(a) >= len(b):
+			i = k * len(a) / parts
+			// Elements of b equal to the pivot stay on the right, behind the elements of a, to keep the merge stable
+			j = sort.Search(len(b), func(x int) bool {
+				return !less(b[x], a[i])
+			})
+		default:
+			j = k * len(b) / parts
+			// Elements of a equal to the pivot go on the left, ahead of the elements of b, to keep the merge stable
+			i = sort.Search(len(a), func(x int) bool {
+				return less(b[j], a[x])
+			})
+		}
+		tasks = append(tasks, mergeTask[T]{a: a[i0:i], b: b[j0:j], dst: dst[i0+j0 : i+j]})
+		i0, j0 = i, j
+	}
+	return tasks
+}
+
+// merge merges the two runs, taking from a whenever the heads are equal
+func (t mergeTask[T]) merge(less func(a T, b T) bool) {
+	a, b := t.a, t.b
+	k := 0
+	for len(a) > 0 && len(b) > 0 {
+		if less(b[0], a[0]) {
+			t.dst[k] = b[0]
+			b = b[1:]
+		} else {
+			t.dst[k] = a[0]
+			a = a[1:]
+		}
+		k++
+	}
+	k += copy(t.dst[k:], a)
+	copy(t.dst[k:], b)
+}
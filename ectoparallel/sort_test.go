@@ -0,0 +1,158 @@
+package ectoparallel
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/Gobusters/ectolinq"
+)
+
+// sortSchedules are the option sets the sort tests run under
+var sortSchedules = map[string][]Option{
+	"Default":       nil,
+	"Three workers": {WithConcurrency(3)},
+	"Eight workers": {WithConcurrency(8)},
+	"Small grain":   {WithConcurrency(4), WithGrainSize(1000)},
+	"Work stealing": {WithConcurrency(4), WorkStealing()},
+}
+
+type sortRecord struct {
+	Key   int
+	Index int
+}
+
+func randomInts(n int, limit int) []int {
+	r := rand.New(rand.NewPCG(1, uint64(n)))
+	items := make([]int, n)
+	for i := range items {
+		items[i] = r.IntN(limit)
+	}
+	return items
+}
+
+func TestSort(t *testing.T) {
+	for name, opts := range sortSchedules {
+		for _, n := range []int{0, 1, 100, sortThreshold, 50001} {
+			t.Run(fmt.Sprintf("%s/%d items", name, n), func(t *testing.T) {
+				items := randomInts(n, 1000)
+				expected := slices.Clone(items)
+				slices.Sort(expected)
+
+				if got := Sort(slices.Clone(items), opts...); !equalSlices(got, expected) {
+					t.Errorf("Sort differs from slices.Sort")
+				}
+				sorted := SortFunc(slices.Clone(items), func(a int, b int) bool { return a < b }, opts...)
+				if !equalSlices(sorted, expected) {
+					t.Errorf("SortFunc differs from slices.Sort")
+				}
+			})
+		}
+	}
+
+	t.Run("Sorts in place", func(t *testing.T) {
+		items := randomInts(3*sortThreshold, 1000)
+		Sort(items, WithConcurrency(4))
+		if !slices.IsSorted(items) {
+			t.Errorf("Expected the slice itself to be sorted")
+		}
+	})
+
+	t.Run("Sorts already sorted and reversed input", func(t *testing.T) {
+		items := make([]int, 3*sortThreshold)
+		for i := range items {
+			items[i] = len(items) - i
+		}
+		SortFunc(items, func(a int, b int) bool { return a < b }, WithConcurrency(4))
+		if !slices.IsSorted(items) {
+			t.Errorf("Expected reversed input to be sorted")
+		}
+		Sort(items, WithConcurrency(4))
+		if !slices.IsSorted(items) {
+			t.Errorf("Expected sorted input to stay sorted")
+		}
+	})
+}
+
+func TestSortStable(t *testing.T) {
+	for name, opts := range sortSchedules {
+		t.Run(name, func(t *testing.T) {
+			keys := randomInts(40000, 50)
+			records := make([]sortRecord, len(keys))
+			for i, key := range keys {
+				records[i] = sortRecord{Key: key, Index: i}
+			}
+			byKey := ectolinq.By(func(r sortRecord) int { return r.Key })
+
+			expected := slices.Clone(records)
+			slices.SortStableFunc(expected, byKey)
+			if got := SortStable(slices.Clone(records), byKey, opts...); !equalSlices(got, expected) {
+				t.Errorf("SortStable differs from slices.SortStableFunc")
+			}
+
+			descending := ectolinq.CompareKeys(byKey.Reverse(), ectolinq.By(func(r sortRecord) int { return -r.Index }))
+			expected = ectolinq.OrderByDescending(records, func(r sortRecord) int { return r.Key }).ThenByDescending(
+				ectolinq.By(func(r sortRecord) int { return r.Index })).ToList()
+			if got := SortStable(slices.Clone(records), descending, opts...); !equalSlices(got, expected) {
+				t.Errorf("SortStable with combined keys differs from OrderByDescending and ThenByDescending")
+			}
+		})
+	}
+}
+
+func TestSortPanic(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*PanicError); !ok {
+			t.Errorf("Expected SortFunc to panic with a PanicError on the calling goroutine")
+		}
+	}()
+	SortFunc(randomInts(2*sortThreshold, 1000), func(a int, b int) bool {
+		if a == 500 {
+			panic("boom")
+		}
+		return a < b
+	}, WithConcurrency(4))
+}
+
+func BenchmarkSort(b *testing.B) {
+	ints := randomInts(1<<20, 1<<30)
+	keys := randomInts(1<<20, 1000)
+	records := make([]sortRecord, len(keys))
+	for i, key := range keys {
+		records[i] = sortRecord{Key: key, Index: i}
+	}
+	compareInts := func(a int, b int) int { return a - b }
+	byKey := ectolinq.By(func(r sortRecord) int { return r.Key })
+
+	b.Run("ints/slices.SortFunc", func(b *testing.B) {
+		for b.Loop() {
+			slices.SortFunc(slices.Clone(ints), compareInts)
+		}
+	})
+	b.Run("ints/ectolinq.SortWhere", func(b *testing.B) {
+		for b.Loop() {
+			ectolinq.SortWhere(slices.Clone(ints), func(a int, b int) bool { return a < b })
+		}
+	})
+	b.Run("ints/Sort", func(b *testing.B) {
+		for b.Loop() {
+			Sort(slices.Clone(ints))
+		}
+	})
+	b.Run("ints/SortFunc", func(b *testing.B) {
+		for b.Loop() {
+			SortFunc(slices.Clone(ints), func(a int, b int) bool { return a < b })
+		}
+	})
+	b.Run("records/slices.SortStableFunc", func(b *testing.B) {
+		for b.Loop() {
+			slices.SortStableFunc(slices.Clone(records), byKey)
+		}
+	})
+	b.Run("records/SortStable", func(b *testing.B) {
+		for b.Loop() {
+			SortStable(slices.Clone(records), byKey)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package ectolinq
+
+// Join correlates the elements of two slices by key and returns one result for every matching pair.
+// Results follow the order of outer, then the order of inner within each outer element
+// outer: The first slice to join
+// inner: The slice to join to the first slice
+// outerKey: The function to extract the join key from each element of outer
+// innerKey: The function to extract the join key from each element of inner
+// resultSelector: The function to create a result from a matching pair
+func Join[T any, U any, K comparable, V any](outer []T, inner []U, outerKey func(T) K, innerKey func(U) K, resultSelector func(T, U) V) []V {
+	lookup := indexByKey(inner, innerKey)
+	var results []V
+
+	for _, item := range outer {
+		for _, index := range lookup[outerKey(item)] {
+			results = append(results, resultSelector(item, inner[index]))
+		}
+	}
+
+	return results
+}
+
+// LeftJoin correlates the elements of two slices by key and keeps every element of outer.
+// The inner value passed to the result selector is nil when an outer element has no match
+// outer: The first slice to join
+// inner: The slice to join to the first slice
+// outerKey: The function to extract the join key from each element of outer
+// innerKey: The function to extract the join key from each element of inner
+// resultSelector: The function to create a result from an outer element and its optional match
+func LeftJoin[T any, U any, K comparable, V any](outer []T, inner []U, outerKey func(T) K, innerKey func(U) K, resultSelector func(T, *U) V) []V {
+	lookup := indexByKey(inner, innerKey)
+	var results []V
+
+	for _, item := range outer {
+		matches := lookup[outerKey(item)]
+		if len(matches) == 0 {
+			results = append(results, resultSelector(item, nil))
+			continue
+		}
+		for _, index := range matches {
+			match := inner[index]
+			results = append(results, resultSelector(item, &match))
+		}
+	}
+
+	return results
+}
+
+// FullOuterJoin correlates the elements of two slices by key and keeps every element of both slices.
+// Either value passed to the result selector is nil when that side has no match. Matched and outer-only
+// results come first in the order of outer, followed by inner-only results in the order of inner
+// outer: The first slice to join
+// inner: The slice to join to the first slice
+// outerKey: The function to extract the join key from each element of outer
+// innerKey: The function to extract the join key from each element of inner
+// resultSelector: The function to create a result from a pair where either side may be missing
+func FullOuterJoin[T any, U any, K comparable, V any](outer []T, inner []U, outerKey func(T) K, innerKey func(U) K, resultSelector func(*T, *U) V) []V {
+	lookup := indexByKey(inner, innerKey)
+	matched := make([]bool, len(inner))
+	var results []V
+
+	for _, item := range outer {
+		matches := lookup[outerKey(item)]
+		if len(matches) == 0 {
+			results = append(results, resultSelector(&item, nil))
+			continue
+		}
+		for _, index := range matches {
+			matched[index] = true
+			match := inner[index]
+			results = append(results, resultSelector(&item, &match))
+		}
+	}
+
+	for index, item := range inner {
+		if !matched[index] {
+			results = append(results, resultSelector(nil, &item))
+		}
+	}
+
+	return results
+}
+
+// GroupJoin correlates each element of outer with the slice of inner elements that share its key.
+// The slice is empty when there are no matches
+// outer: The first slice to join
+// inner: The slice to join to the first slice
+// outerKey: The function to extract the join key from each element of outer
+// innerKey: The function to extract the join key from each element of inner
+// resultSelector: The function to create a result from an outer element and its matches
+func GroupJoin[T any, U any, K comparable, V any](outer []T, inner []U, outerKey func(T) K, innerKey func(U) K, resultSelector func(T, []U) V) []V {
+	groups := GroupWhere(inner, innerKey)
+	results := make([]V, 0, len(outer))
+
+	for _, item := range outer {
+		matches := groups[outerKey(item)]
+		if matches == nil {
+			matches = []U{}
+		}
+		results = append(results, resultSelector(item, matches))
+	}
+
+	return results
+}
+
+// indexByKey maps each key to the positions of the elements that have it, in slice order
+func indexByKey[T any, K comparable](items []T, keySelector func(T) K) map[K][]int {
+	lookup := make(map[K][]int, len(items))
+	for index, item := range items {
+		key := keySelector(item)
+		lookup[key] = append(lookup[key], index)
+	}
+	return lookup
+}
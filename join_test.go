@@ -0,0 +1,92 @@
+package ectolinq
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Gobusters/ectolinq/pointer"
+	"github.com/stretchr/testify/assert"
+)
+
+type joinCustomer struct {
+	ID   int
+	Name string
+}
+
+type joinOrder struct {
+	CustomerID int
+	Item       string
+}
+
+var joinCustomers = []joinCustomer{
+	{ID: 1, Name: "Ada"},
+	{ID: 2, Name: "Grace"},
+	{ID: 3, Name: "Alan"},
+}
+
+var joinOrders = []joinOrder{
+	{CustomerID: 2, Item: "compiler"},
+	{CustomerID: 1, Item: "engine"},
+	{CustomerID: 2, Item: "moth"},
+	{CustomerID: 4, Item: "orphan"},
+}
+
+func customerID(c joinCustomer) int   { return c.ID }
+func orderCustomerID(o joinOrder) int { return o.CustomerID }
+
+func TestJoin(t *testing.T) {
+	t.Run("Inner join", func(t *testing.T) {
+		result := Join(joinCustomers, joinOrders, customerID, orderCustomerID, func(c joinCustomer, o joinOrder) string {
+			return c.Name + ":" + o.Item
+		})
+		assert.Equal(t, []string{"Ada:engine", "Grace:compiler", "Grace:moth"}, result)
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		result := Join(joinCustomers, []joinOrder{}, customerID, orderCustomerID, func(c joinCustomer, o joinOrder) string {
+			return c.Name
+		})
+		assert.Empty(t, result)
+	})
+}
+
+func TestLeftJoin(t *testing.T) {
+	result := LeftJoin(joinCustomers, joinOrders, customerID, orderCustomerID, func(c joinCustomer, o *joinOrder) string {
+		if o == nil {
+			return c.Name + ":-"
+		}
+		return c.Name + ":" + o.Item
+	})
+	assert.Equal(t, []string{"Ada:engine", "Grace:compiler", "Grace:moth", "Alan:-"}, result)
+}
+
+func TestFullOuterJoin(t *testing.T) {
+	result := FullOuterJoin(joinCustomers, joinOrders, customerID, orderCustomerID, func(c *joinCustomer, o *joinOrder) string {
+		return fmt.Sprintf("%s:%s", pointer.Safe(c).Name, pointer.Safe(o).Item)
+	})
+	assert.Equal(t, []string{"Ada:engine", "Grace:compiler", "Grace:moth", "Alan:", ":orphan"}, result)
+}
+
+func TestGroupJoin(t *testing.T) {
+	t.Run("Group matches per outer element", func(t *testing.T) {
+		result := GroupJoin(joinCustomers, joinOrders, customerID, orderCustomerID, func(c joinCustomer, orders []joinOrder) string {
+			return fmt.Sprintf("%s:%d", c.Name, len(orders))
+		})
+		assert.Equal(t, []string{"Ada:1", "Grace:2", "Alan:0"}, result)
+	})
+
+	t.Run("Unmatched elements get an empty slice", func(t *testing.T) {
+		result := GroupJoin(joinCustomers, joinOrders, customerID, orderCustomerID, func(c joinCustomer, orders []joinOrder) []joinOrder {
+			return orders
+		})
+		assert.NotNil(t, result[2])
+		assert.Empty(t, result[2])
+	})
+
+	t.Run("Keys of different types can be projected to a common key", func(t *testing.T) {
+		result := GroupJoin([]string{"1", "2"}, []int{1, 1, 2}, func(s string) string { return s }, func(n int) string {
+			return fmt.Sprint(n)
+		}, func(s string, matches []int) int { return len(matches) })
+		assert.Equal(t, []int{2, 1}, result)
+	})
+}
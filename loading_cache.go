@@ -0,0 +1,334 @@
+package ectolinq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotLoaded is returned by LoadingCache.GetAll for a key that the batch loader left out of its result
+var ErrKeyNotLoaded = errors.New("loader returned no value for key")
+
+// LoadingCache is a thread-safe cache that loads missing values on demand.
+// Concurrent misses for the same key share a single call to the loader, so an expensive load runs at most once per key at a time.
+// Loaded values can expire and be refreshed in the background before they do, and load errors can be cached so a failing key is not retried on every call.
+// The entries are held in a ConcurrentDictionary
+type LoadingCache[K comparable, V any] struct {
+	entries      *ConcurrentDictionary[K, loadingEntry[V]]
+	load         func(context.Context, K) (V, error)
+	loadAll      func(context.Context, []K) (map[K]V, error)
+	ttl          time.Duration
+	errorTTL     time.Duration
+	refreshAhead time.Duration
+	clock        func() time.Time
+	// mutex guards calls. It is held while a finished load is stored, so a load is never cached after its key was removed or set
+	mutex sync.Mutex
+	calls map[K]*loadCall[V]
+}
+
+// loadingEntry is a loaded value or a cached load error, with the time it expires. A zero expiry never expires
+type loadingEntry[V any] struct {
+	value   V
+	err     error
+	expires time.Time
+}
+
+// loadCall is a load in flight. done is closed once value and err are set
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// LoadingCacheOptions configures a LoadingCache created with NewLoadingCacheWithOptions
+type LoadingCacheOptions[K comparable, V any] struct {
+	// LoadAll loads several keys in one call for GetAll. Keys missing from the result fail with ErrKeyNotLoaded.
+	// Nil makes GetAll call the single key loader for each missing key concurrently
+	LoadAll func(ctx context.Context, keys []K) (map[K]V, error)
+	// TTL is how long loaded values are kept. Zero means they never expire
+	TTL time.Duration
+	// ErrorTTL is how long a load error is kept and returned without calling the loader again. Zero means errors are not cached
+	ErrorTTL time.Duration
+	// RefreshAhead starts a background reload when a value is read less than this long before it expires, returning the current value meanwhile.
+	// Zero disables refreshing, as does a TTL of zero
+	RefreshAhead time.Duration
+	// ShardCount is the number of shards in the underlying ConcurrentDictionary. Zero or less uses DefaultShardCount
+	ShardCount int
+	// JanitorInterval is how often expired entries are deleted in the background. Zero removes them lazily. Call Close to stop the janitor
+	JanitorInterval time.Duration
+	// Clock returns the current time used for expiry. Nil uses time.Now
+	Clock func() time.Time
+}
+
+// NewLoadingCache creates a new loading cache whose values never expire
+// load: The function that loads the value for a key
+func NewLoadingCache[K comparable, V any](load func(ctx context.Context, key K) (V, error)) *LoadingCache[K, V] {
+	return NewLoadingCacheWithOptions(load, LoadingCacheOptions[K, V]{})
+}
+
+// NewLoadingCacheWithOptions creates a new loading cache with the given options
+// load: The function that loads the value for a key
+// options: The batch loader, expiry and refresh options to use
+func NewLoadingCacheWithOptions[K comparable, V any](load func(ctx context.Context, key K) (V, error), options LoadingCacheOptions[K, V]) *LoadingCache[K, V] {
+	clock := options.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+	return &LoadingCache[K, V]{
+		entries: NewConcurrentDictionaryWithOptions(ConcurrentDictionaryOptions[K, loadingEntry[V]]{
+			ShardCount:      options.ShardCount,
+			JanitorInterval: options.JanitorInterval,
+			Clock:           clock,
+		}),
+		load:         load,
+		loadAll:      options.LoadAll,
+		ttl:          max(options.TTL, 0),
+		errorTTL:     max(options.ErrorTTL, 0),
+		refreshAhead: max(options.RefreshAhead, 0),
+		clock:        clock,
+		calls:        make(map[K]*loadCall[V]),
+	}
+}
+
+// Get returns the value for the given key, calling the loader if it is not cached.
+// If a load for the key is already in flight, Get waits for it instead of starting another.
+// It returns the load error, or the context error if the context is done first; the load carries on for any other callers
+// ctx: The context to wait with. The loader receives its values but not its cancellation
+// key: The key to get the value for
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	if entry, ok := c.cached(ctx, key); ok {
+		return entry.value, entry.err
+	}
+	call, leader := c.begin(key, true)
+	if leader {
+		go c.loadOne(context.WithoutCancel(ctx), key, call, false)
+	}
+	return call.wait(ctx)
+}
+
+// GetAll returns the values for the given keys, loading every missing key with a single call to LoadAll.
+// Keys that are already being loaded are waited for rather than loaded again.
+// The map holds each key that loaded. If any key failed, the error for the first of them is returned as well
+// ctx: The context to wait with. The loader receives its values but not its cancellation
+// keys: The keys to get the values for
+func (c *LoadingCache[K, V]) GetAll(ctx context.Context, keys []K) (map[K]V, error) {
+	values := make(map[K]V, len(keys))
+	calls := make(map[K]*loadCall[V])
+	errs := make(map[K]error)
+	var missing []K
+	var leaders []*loadCall[V]
+	for _, key := range keys {
+		if _, ok := calls[key]; ok {
+			continue
+		}
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if _, ok := errs[key]; ok {
+			continue
+		}
+		if entry, ok := c.cached(ctx, key); ok {
+			if entry.err != nil {
+				errs[key] = entry.err
+			} else {
+				values[key] = entry.value
+			}
+			continue
+		}
+		call, leader := c.begin(key, true)
+		calls[key] = call
+		if leader {
+			missing = append(missing, key)
+			leaders = append(leaders, call)
+		}
+	}
+
+	if len(missing) > 0 {
+		loadCtx := context.WithoutCancel(ctx)
+		if c.loadAll == nil {
+			for i, key := range missing {
+				go c.loadOne(loadCtx, key, leaders[i], false)
+			}
+		} else {
+			go c.loadMany(loadCtx, missing, leaders)
+		}
+	}
+
+	var err error
+	for _, key := range keys {
+		if call, ok := calls[key]; ok {
+			value, loadErr := call.wait(ctx)
+			if ctx.Err() != nil {
+				return values, ctx.Err()
+			}
+			if loadErr != nil {
+				errs[key] = loadErr
+			} else {
+				values[key] = value
+			}
+			delete(calls, key)
+		}
+		if err == nil {
+			err = errs[key]
+		}
+	}
+	return values, err
+}
+
+// GetIfPresent returns the cached value for the given key without loading it. A cached load error is reported as missing
+// key: The key to get the value for
+func (c *LoadingCache[K, V]) GetIfPresent(key K) (V, bool) {
+	entry, ok := c.entries.Get(key)
+	if !ok || entry.err != nil {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Set caches a value for the given key as though it had been loaded. A load of the key that is in flight is not cached when it finishes
+// key: The key to set the value for
+// value: The value to set
+func (c *LoadingCache[K, V]) Set(key K, value V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.calls, key)
+	c.store(key, value, nil, false)
+}
+
+// Remove removes the cached value or error for the given key. A load of the key that is in flight is not cached when it finishes
+// key: The key to remove
+func (c *LoadingCache[K, V]) Remove(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.calls, key)
+	c.entries.Remove(key)
+}
+
+// Clear removes all cached values and errors. Loads that are in flight are not cached when they finish
+func (c *LoadingCache[K, V]) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	clear(c.calls)
+	c.entries.Clear()
+}
+
+// Count returns the number of cached values and errors that have not expired
+func (c *LoadingCache[K, V]) Count() int {
+	return c.entries.Count()
+}
+
+// Close stops the background janitor, if there is one. The cache can still be used afterwards
+func (c *LoadingCache[K, V]) Close() {
+	c.entries.Close()
+}
+
+// cached returns the cached entry for the key, starting a background refresh if the value is close to expiring
+func (c *LoadingCache[K, V]) cached(ctx context.Context, key K) (loadingEntry[V], bool) {
+	entry, ok := c.entries.Get(key)
+	if ok && entry.err == nil && c.refreshAhead > 0 && !entry.expires.IsZero() && !c.clock().Before(entry.expires.Add(-c.refreshAhead)) {
+		if call, leader := c.begin(key, false); leader {
+			go c.loadOne(context.WithoutCancel(ctx), key, call, true)
+		}
+	}
+	return entry, ok
+}
+
+// begin returns the load in flight for the key, or registers a new load that the caller must run when leader is true.
+// When missing is set and a load has cached the key since the caller looked, its result is returned as a finished load
+func (c *LoadingCache[K, V]) begin(key K, missing bool) (call *loadCall[V], leader bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if call, ok := c.calls[key]; ok {
+		return call, false
+	}
+	call = &loadCall[V]{done: make(chan struct{})}
+	if missing {
+		if entry, ok := c.entries.Get(key); ok {
+			call.value, call.err = entry.value, entry.err
+			close(call.done)
+			return call, false
+		}
+	}
+	c.calls[key] = call
+	return call, true
+}
+
+// loadOne runs the single key loader for a load registered by begin
+func (c *LoadingCache[K, V]) loadOne(ctx context.Context, key K, call *loadCall[V], refresh bool) {
+	value, err := recoverLoad(func() (V, error) {
+		return c.load(ctx, key)
+	})
+	c.finish(key, call, value, err, refresh)
+}
+
+// loadMany runs the batch loader for loads registered by begin. calls holds the load for each key
+func (c *LoadingCache[K, V]) loadMany(ctx context.Context, keys []K, calls []*loadCall[V]) {
+	values, err := recoverLoad(func() (map[K]V, error) {
+		return c.loadAll(ctx, keys)
+	})
+	for i, key := range keys {
+		value, ok := values[key]
+		switch {
+		case err != nil:
+			c.finish(key, calls[i], value, err, false)
+		case !ok:
+			c.finish(key, calls[i], value, fmt.Errorf("%w: %v", ErrKeyNotLoaded, key), false)
+		default:
+			c.finish(key, calls[i], value, nil, false)
+		}
+	}
+}
+
+// finish caches the result of a load and wakes its waiters.
+// Nothing is cached if the key was removed or set while the load was in flight
+func (c *LoadingCache[K, V]) finish(key K, call *loadCall[V], value V, err error, refresh bool) {
+	call.value, call.err = value, err
+	c.mutex.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+		c.store(key, value, err, refresh)
+	}
+	c.mutex.Unlock()
+	close(call.done)
+}
+
+// store caches a load result. Errors are only cached when ErrorTTL is set, and a failed refresh keeps the current value until it expires
+func (c *LoadingCache[K, V]) store(key K, value V, err error, refresh bool) {
+	switch {
+	case err == nil:
+		c.put(key, loadingEntry[V]{value: value}, c.ttl)
+	case c.errorTTL > 0 && !refresh:
+		c.put(key, loadingEntry[V]{err: err}, c.errorTTL)
+	}
+}
+
+func (c *LoadingCache[K, V]) put(key K, entry loadingEntry[V], ttl time.Duration) {
+	if ttl > 0 {
+		entry.expires = c.clock().Add(ttl)
+	}
+	c.entries.SetWithTTL(key, entry, ttl)
+}
+
+// wait returns the result of the load, or the context error if the context is done first
+func (call *loadCall[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// recoverLoad runs a loader, turning a panic into an error so it reaches every caller waiting on the load
+func recoverLoad[T any](fn func() (T, error)) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("loader panicked: %v", r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,354 @@
+package ectolinq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLoader loads a key as its length and counts how many times each key was loaded
+type countingLoader struct {
+	mutex sync.Mutex
+	loads map[string]int
+}
+
+func (l *countingLoader) Load(_ context.Context, key string) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.loads == nil {
+		l.loads = make(map[string]int)
+	}
+	l.loads[key]++
+	return len(key), nil
+}
+
+func (l *countingLoader) Loads(key string) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.loads[key]
+}
+
+func TestLoadingCacheGet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Loads a missing key once and caches it", func(t *testing.T) {
+		loader := &countingLoader{}
+		c := NewLoadingCache(loader.Load)
+		for range 3 {
+			value, err := c.Get(ctx, "abc")
+			require.NoError(t, err)
+			assert.Equal(t, 3, value)
+		}
+		assert.Equal(t, 1, loader.Loads("abc"))
+		assert.Equal(t, 1, c.Count())
+	})
+
+	t.Run("Concurrent misses share a single load", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		c := NewLoadingCache(func(_ context.Context, key string) (int, error) {
+			loads.Add(1)
+			<-release
+			return len(key), nil
+		})
+
+		var wg sync.WaitGroup
+		results := make([]int, 50)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = c.Get(ctx, "abcd")
+			}()
+		}
+		assert.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), loads.Load())
+		assert.Equal(t, slices.Repeat([]int{4}, 50), results)
+	})
+
+	t.Run("Reloads a value once it expires", func(t *testing.T) {
+		clock := newFakeClock()
+		loader := &countingLoader{}
+		c := NewLoadingCacheWithOptions(loader.Load, LoadingCacheOptions[string, int]{TTL: time.Minute, Clock: clock.Now})
+		c.Get(ctx, "a")
+		clock.Advance(59 * time.Second)
+		c.Get(ctx, "a")
+		assert.Equal(t, 1, loader.Loads("a"))
+		assert.Equal(t, 1, c.Count())
+		clock.Advance(time.Second)
+		assert.Equal(t, 0, c.Count(), "An expired value should not be counted")
+		c.Get(ctx, "a")
+		assert.Equal(t, 2, loader.Loads("a"))
+	})
+
+	t.Run("Errors are not cached by default", func(t *testing.T) {
+		var loads atomic.Int32
+		failure := errors.New("unavailable")
+		c := NewLoadingCache(func(context.Context, string) (int, error) {
+			loads.Add(1)
+			return 0, failure
+		})
+		_, err := c.Get(ctx, "a")
+		assert.ErrorIs(t, err, failure)
+		_, err = c.Get(ctx, "a")
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, int32(2), loads.Load())
+		assert.Equal(t, 0, c.Count())
+	})
+
+	t.Run("Errors are cached for ErrorTTL", func(t *testing.T) {
+		clock := newFakeClock()
+		var loads atomic.Int32
+		c := NewLoadingCacheWithOptions(func(context.Context, string) (int, error) {
+			loads.Add(1)
+			return 0, errors.New("unavailable")
+		}, LoadingCacheOptions[string, int]{TTL: time.Hour, ErrorTTL: time.Second, Clock: clock.Now})
+
+		_, err := c.Get(ctx, "a")
+		assert.Error(t, err)
+		_, err = c.Get(ctx, "a")
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), loads.Load())
+		_, ok := c.GetIfPresent("a")
+		assert.False(t, ok, "A cached error should not be present")
+
+		clock.Advance(time.Second)
+		c.Get(ctx, "a")
+		assert.Equal(t, int32(2), loads.Load())
+	})
+
+	t.Run("A cancelled caller stops waiting but the load still completes", func(t *testing.T) {
+		release := make(chan struct{})
+		c := NewLoadingCache(func(ctx context.Context, key string) (int, error) {
+			<-release
+			return len(key), ctx.Err()
+		})
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := c.Get(cancelled, "ab")
+		assert.ErrorIs(t, err, context.Canceled)
+
+		close(release)
+		value, err := c.Get(ctx, "ab")
+		require.NoError(t, err, "The loader should not see the caller's cancellation")
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("A panicking loader returns an error", func(t *testing.T) {
+		c := NewLoadingCache(func(context.Context, string) (int, error) {
+			panic("boom")
+		})
+		_, err := c.Get(ctx, "a")
+		assert.ErrorContains(t, err, "boom")
+	})
+}
+
+func TestLoadingCacheRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	var version atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	c := NewLoadingCacheWithOptions(func(context.Context, string) (int, error) {
+		defer func() {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+		}()
+		return int(version.Add(1)), nil
+	}, LoadingCacheOptions[string, int]{TTL: 10 * time.Minute, RefreshAhead: 2 * time.Minute, Clock: clock.Now})
+
+	value, _ := c.Get(ctx, "a")
+	assert.Equal(t, 1, value)
+	<-refreshed
+
+	clock.Advance(7 * time.Minute)
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, 1, value)
+	assert.Equal(t, int32(1), version.Load(), "No refresh should start before the refresh window")
+
+	clock.Advance(2 * time.Minute)
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, 1, value, "The current value should be returned while it refreshes")
+	<-refreshed
+	assert.Eventually(t, func() bool {
+		value, _ := c.GetIfPresent("a")
+		return value == 2
+	}, time.Second, time.Millisecond)
+
+	clock.Advance(9 * time.Minute)
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, 2, value, "The refreshed value should have a new expiry")
+}
+
+func TestLoadingCacheGetAll(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Batches misses into one LoadAll call", func(t *testing.T) {
+		var mutex sync.Mutex
+		var batches [][]string
+		c := NewLoadingCacheWithOptions(nil, LoadingCacheOptions[string, int]{
+			LoadAll: func(_ context.Context, keys []string) (map[string]int, error) {
+				mutex.Lock()
+				batches = append(batches, keys)
+				mutex.Unlock()
+				values := map[string]int{}
+				for _, key := range keys {
+					if key != "missing" {
+						values[key] = len(key)
+					}
+				}
+				return values, nil
+			},
+		})
+		c.Set("a", 100)
+
+		values, err := c.GetAll(ctx, []string{"a", "bb", "ccc", "bb", "missing"})
+		assert.ErrorIs(t, err, ErrKeyNotLoaded)
+		assert.Equal(t, map[string]int{"a": 100, "bb": 2, "ccc": 3}, values)
+		assert.ElementsMatch(t, []string{"bb", "ccc", "missing"}, batches[0])
+		assert.Len(t, batches, 1)
+
+		values, err = c.GetAll(ctx, []string{"bb", "ccc"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"bb": 2, "ccc": 3}, values)
+		assert.Len(t, batches, 1, "Loaded keys should be cached")
+	})
+
+	t.Run("Falls back to the single key loader", func(t *testing.T) {
+		loader := &countingLoader{}
+		c := NewLoadingCache(loader.Load)
+		values, err := c.GetAll(ctx, []string{"a", "bb"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 1, "bb": 2}, values)
+		assert.Equal(t, 1, loader.Loads("a"))
+		assert.Equal(t, 1, loader.Loads("bb"))
+	})
+
+	t.Run("Returns the batch error for every missing key", func(t *testing.T) {
+		failure := errors.New("unavailable")
+		c := NewLoadingCacheWithOptions(nil, LoadingCacheOptions[string, int]{
+			LoadAll: func(context.Context, []string) (map[string]int, error) {
+				return nil, failure
+			},
+		})
+		c.Set("a", 1)
+		values, err := c.GetAll(ctx, []string{"a", "b", "c"})
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, map[string]int{"a": 1}, values)
+	})
+
+	t.Run("Waits for keys already being loaded", func(t *testing.T) {
+		release := make(chan struct{})
+		var batched atomic.Int32
+		c := NewLoadingCacheWithOptions(func(_ context.Context, key string) (int, error) {
+			<-release
+			return len(key), nil
+		}, LoadingCacheOptions[string, int]{
+			LoadAll: func(_ context.Context, keys []string) (map[string]int, error) {
+				batched.Add(int32(len(keys)))
+				values := map[string]int{}
+				for _, key := range keys {
+					values[key] = len(key)
+				}
+				return values, nil
+			},
+		})
+
+		single := make(chan int)
+		go func() {
+			value, _ := c.Get(ctx, "slow")
+			single <- value
+		}()
+		assert.Eventually(t, func() bool {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			return len(c.calls) == 1
+		}, time.Second, time.Millisecond)
+
+		done := make(chan map[string]int)
+		go func() {
+			values, _ := c.GetAll(ctx, []string{"slow", "b"})
+			done <- values
+		}()
+		close(release)
+		assert.Equal(t, map[string]int{"slow": 4, "b": 1}, <-done)
+		assert.Equal(t, 4, <-single)
+		assert.Equal(t, int32(1), batched.Load(), "Only the key not already loading should be batched")
+	})
+}
+
+func TestLoadingCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Remove and Clear drop cached values", func(t *testing.T) {
+		loader := &countingLoader{}
+		c := NewLoadingCache(loader.Load)
+		c.Get(ctx, "a")
+		c.Get(ctx, "b")
+		c.Remove("a")
+		_, ok := c.GetIfPresent("a")
+		assert.False(t, ok)
+		c.Get(ctx, "a")
+		assert.Equal(t, 2, loader.Loads("a"))
+
+		c.Clear()
+		assert.Equal(t, 0, c.Count())
+	})
+
+	t.Run("A load in flight is not cached after Remove", func(t *testing.T) {
+		release := make(chan struct{})
+		var loads atomic.Int32
+		c := NewLoadingCache(func(_ context.Context, key string) (string, error) {
+			n := loads.Add(1)
+			if n == 1 {
+				<-release
+			}
+			return fmt.Sprint(key, n), nil
+		})
+
+		first := make(chan string)
+		go func() {
+			value, _ := c.Get(ctx, "a")
+			first <- value
+		}()
+		assert.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+		c.Remove("a")
+		close(release)
+		assert.Equal(t, "a1", <-first, "Callers waiting on the load still get its result")
+
+		_, ok := c.GetIfPresent("a")
+		assert.False(t, ok, "The stale load should not be cached")
+		value, _ := c.Get(ctx, "a")
+		assert.Equal(t, "a2", value)
+	})
+
+	t.Run("Set overrides a load in flight", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		c := NewLoadingCache(func(context.Context, string) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		go c.Get(ctx, "a")
+		<-started
+		c.Set("a", 2)
+		close(release)
+		assert.Never(t, func() bool {
+			value, _ := c.GetIfPresent("a")
+			return value != 2
+		}, 50*time.Millisecond, time.Millisecond)
+	})
+}
@@ -0,0 +1,159 @@
+package ectolinq
+
+import (
+	"iter"
+	"slices"
+)
+
+// Lookup is a multimap that associates each key with one or more values.
+// Keys are kept in the order they were first added
+type Lookup[K comparable, V any] struct {
+	keys   []K
+	values map[K][]V
+}
+
+// NewLookup creates a new lookup
+func NewLookup[K comparable, V any]() *Lookup[K, V] {
+	return &Lookup[K, V]{
+		values: make(map[K][]V),
+	}
+}
+
+// ToLookup creates a new lookup from the result of Group or GroupWhere.
+// Keys are ordered as the map yields them, which Go does not specify
+// groups: The map of keys to values to create the lookup from
+func ToLookup[K comparable, V any](groups map[K][]V) *Lookup[K, V] {
+	lookup := NewLookup[K, V]()
+	for key, values := range groups {
+		lookup.Add(key, values...)
+	}
+	return lookup
+}
+
+// GroupLookupWhere returns a lookup of the slice where the key is the result of the selector function.
+// Keys are ordered by the first element that produced them
+// items: The slice to convert to a lookup
+// selector: The selector function to use
+func GroupLookupWhere[T any, K comparable](items []T, selector func(T) K) *Lookup[K, T] {
+	lookup := NewLookup[K, T]()
+	for _, item := range items {
+		lookup.Add(selector(item), item)
+	}
+	return lookup
+}
+
+// GroupLookup returns a lookup of the slice where the key is the value of the field at the specified path
+// items: The slice to convert to a lookup
+// path: The path to the field to use as the key
+func GroupLookup[T any, K comparable](items []T, path string) *Lookup[K, T] {
+	return GroupLookupWhere(items, func(item T) K {
+		value, _ := Get(item, path)
+		casted, _ := value.(K)
+		return casted
+	})
+}
+
+// Get returns a copy of the values for the given key, or an empty slice if the key is not present
+// key: The key to get the values for
+func (l *Lookup[K, V]) Get(key K) []V {
+	return slices.Clone(l.values[key])
+}
+
+// Contains returns if the lookup contains the given key
+// key: The key to check for
+func (l *Lookup[K, V]) Contains(key K) bool {
+	_, ok := l.values[key]
+	return ok
+}
+
+// Add appends values to the given key, adding the key if it is not present. Without values it does nothing,
+// so every key in the lookup has at least one value
+// key: The key to add the values to
+// values: The values to add
+func (l *Lookup[K, V]) Add(key K, values ...V) {
+	if len(values) == 0 {
+		return
+	}
+	if _, ok := l.values[key]; !ok {
+		l.keys = append(l.keys, key)
+		l.values[key] = nil
+	}
+	l.values[key] = append(l.values[key], values...)
+}
+
+// Remove removes the given key and all of its values
+// key: The key to remove
+func (l *Lookup[K, V]) Remove(key K) {
+	if _, ok := l.values[key]; !ok {
+		return
+	}
+	delete(l.values, key)
+	l.keys = slices.DeleteFunc(l.keys, func(k K) bool { return k == key })
+}
+
+// RemoveValue removes every occurrence of the given value. Keys left without values are removed
+// value: The value to remove
+func (l *Lookup[K, V]) RemoveValue(value V) {
+	l.RemoveWhere(func(_ K, v V) bool {
+		return Equals(v, value)
+	})
+}
+
+// RemoveWhere removes every value that satisfies the given predicate. Keys left without values are removed
+// fn: The predicate to check for
+func (l *Lookup[K, V]) RemoveWhere(fn func(K, V) bool) {
+	for _, key := range slices.Clone(l.keys) {
+		values := Filter(l.values[key], func(v V) bool {
+			return !fn(key, v)
+		})
+		if len(values) == 0 {
+			l.Remove(key)
+			continue
+		}
+		l.values[key] = values
+	}
+}
+
+// Keys returns the keys in the order they were first added
+func (l *Lookup[K, V]) Keys() []K {
+	return slices.Clone(l.keys)
+}
+
+// Count returns the number of keys in the lookup
+func (l *Lookup[K, V]) Count() int {
+	return len(l.keys)
+}
+
+// All returns an iterator over each key and a copy of its values, in key order
+func (l *Lookup[K, V]) All() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for _, key := range l.keys {
+			if !yield(key, l.Get(key)) {
+				return
+			}
+		}
+	}
+}
+
+// ToArray returns every value in the lookup, grouped by key in key order
+func (l *Lookup[K, V]) ToArray() []V {
+	var values []V
+	for _, key := range l.keys {
+		values = append(values, l.values[key]...)
+	}
+	return values
+}
+
+// ToList returns every value in the lookup as a list, grouped by key in key order
+func (l *Lookup[K, V]) ToList() List[V] {
+	return l.ToArray()
+}
+
+// ToMap returns the lookup as a map of keys to values
+func (l *Lookup[K, V]) ToMap() map[K][]V {
+	m := make(map[K][]V, len(l.values))
+	for key, values := range l.values {
+		m[key] = slices.Clone(values)
+	}
+	return m
+}
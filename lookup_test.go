@@ -0,0 +1,130 @@
+package ectolinq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lookupPet struct {
+	Name    string
+	Species string
+}
+
+var lookupPets = []lookupPet{
+	{Name: "Rex", Species: "dog"},
+	{Name: "Tom", Species: "cat"},
+	{Name: "Fido", Species: "dog"},
+	{Name: "Polly", Species: "parrot"},
+}
+
+func TestNewLookup(t *testing.T) {
+	l := NewLookup[string, int]()
+	require.NotNil(t, l, "NewLookup should not return nil")
+	assert.Equal(t, 0, l.Count(), "New lookup should be empty")
+	assert.Empty(t, l.Get("missing"), "Get should return an empty slice for a missing key")
+}
+
+func TestGroupLookupWhere(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	assert.Equal(t, []string{"dog", "cat", "parrot"}, l.Keys(), "Keys should be in first-seen order")
+	assert.Equal(t, []lookupPet{lookupPets[0], lookupPets[2]}, l.Get("dog"), "Get should return all values for the key")
+	assert.Equal(t, 3, l.Count(), "Count should return the number of keys")
+}
+
+func TestGroupLookup(t *testing.T) {
+	l := GroupLookup[lookupPet, string](lookupPets, "Species")
+	assert.Equal(t, []string{"dog", "cat", "parrot"}, l.Keys(), "Keys should be in first-seen order")
+	assert.Len(t, l.Get("dog"), 2, "Get should return all values for the key")
+}
+
+func TestToLookup(t *testing.T) {
+	groups := GroupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	l := ToLookup(groups)
+	assert.ElementsMatch(t, []string{"dog", "cat", "parrot"}, l.Keys(), "ToLookup should contain every group")
+	assert.Equal(t, groups, l.ToMap(), "ToMap should round-trip the groups")
+}
+
+func TestLookupAddAndContains(t *testing.T) {
+	l := NewLookup[string, int]()
+	l.Add("a", 1)
+	l.Add("b", 2, 3)
+	l.Add("a", 4)
+	assert.True(t, l.Contains("a"), "Contains should return true for an added key")
+	assert.False(t, l.Contains("c"), "Contains should return false for a missing key")
+	assert.Equal(t, []int{1, 4}, l.Get("a"), "Add should append to an existing key")
+	assert.Equal(t, []string{"a", "b"}, l.Keys(), "Re-adding a key should not move it")
+}
+
+func TestLookupGetDoesNotShareBackingArray(t *testing.T) {
+	l := NewLookup[string, int]()
+	l.Add("a", 1, 2)
+	values := l.Get("a")
+	_ = append(values, 99)
+	l.Add("a", 3)
+	assert.Equal(t, []int{1, 2}, values, "Appending to a result should not affect the lookup")
+	assert.Equal(t, []int{1, 2, 3}, l.Get("a"))
+
+	l.Get("a")[0] = 99
+	for _, values := range l.All() {
+		values[1] = 99
+	}
+	assert.Equal(t, []int{1, 2, 3}, l.Get("a"), "Changing a result should not affect the lookup")
+}
+
+func TestLookupAddWithoutValues(t *testing.T) {
+	l := NewLookup[string, int]()
+	l.Add("a")
+	assert.False(t, l.Contains("a"), "Add without values should not add the key")
+	assert.Empty(t, l.Keys())
+	assert.Equal(t, 0, l.Count())
+
+	l.Add("b", 1)
+	l.Add("b")
+	assert.Equal(t, []int{1}, l.Get("b"))
+}
+
+func TestLookupRemove(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	l.Remove("cat")
+	assert.False(t, l.Contains("cat"), "Remove should remove the key")
+	assert.Equal(t, []string{"dog", "parrot"}, l.Keys(), "Remove should keep the order of the remaining keys")
+}
+
+func TestLookupRemoveValue(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	l.RemoveValue(lookupPets[0])
+	assert.Equal(t, []lookupPet{lookupPets[2]}, l.Get("dog"), "RemoveValue should remove the value")
+
+	l.RemoveValue(lookupPets[1])
+	assert.False(t, l.Contains("cat"), "RemoveValue should remove keys left empty")
+}
+
+func TestLookupRemoveWhere(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	l.RemoveWhere(func(species string, p lookupPet) bool { return species == "dog" || p.Name == "Polly" })
+	assert.Equal(t, []string{"cat"}, l.Keys(), "RemoveWhere should remove matching values and empty keys")
+}
+
+func TestLookupAll(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	var keys []string
+	counts := 0
+	for key, values := range l.All() {
+		keys = append(keys, key)
+		counts += len(values)
+		if key == "cat" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"dog", "cat"}, keys, "All should iterate in key order and support early exit")
+	assert.Equal(t, 3, counts)
+}
+
+func TestLookupToList(t *testing.T) {
+	l := GroupLookupWhere(lookupPets, func(p lookupPet) string { return p.Species })
+	names := Map(l.ToList(), func(p lookupPet) string { return p.Name })
+	assert.Equal(t, []string{"Rex", "Fido", "Tom", "Polly"}, names, "ToList should flatten values in key order")
+	assert.Len(t, l.ToArray(), 4)
+}
@@ -0,0 +1,369 @@
+package ectolinq
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SortKey compares two elements by a single key.
+// It returns a negative number when a sorts before b, a positive number when a sorts after b and zero when they tie
+type SortKey[T any] func(a T, b T) int
+
+// By returns a SortKey that orders elements ascending by the value returned from the selector
+// selector: The function to extract the key from each element
+func By[T any, K cmp.Ordered](selector func(T) K) SortKey[T] {
+	return func(a T, b T) int {
+		return cmp.Compare(selector(a), selector(b))
+	}
+}
+
+// Reverse returns a SortKey that orders elements in the opposite direction
+func (k SortKey[T]) Reverse() SortKey[T] {
+	return func(a T, b T) int {
+		return k(b, a)
+	}
+}
+
+// OrderedList is a List stably sorted by one or more keys. Use ThenBy and ThenByDescending to break ties.
+// The source slice is never modified
+type OrderedList[T any] struct {
+	List[T]
+	keys []SortKey[T]
+}
+
+// OrderBy returns a copy of the slice stably sorted ascending by the key selector
+// items: The slice to sort
+// selector: The function to extract the key from each element
+func OrderBy[T any, K cmp.Ordered](items []T, selector func(T) K) OrderedList[T] {
+	return orderByKeys(items, By(selector))
+}
+
+// OrderByDescending returns a copy of the slice stably sorted descending by the key selector
+// items: The slice to sort
+// selector: The function to extract the key from each element
+func OrderByDescending[T any, K cmp.Ordered](items []T, selector func(T) K) OrderedList[T] {
+	return orderByKeys(items, By(selector).Reverse())
+}
+
+// ThenBy breaks ties in an ordered list ascending by the key selector
+// ordered: The ordered list to refine
+// selector: The function to extract the key from each element
+func ThenBy[T any, K cmp.Ordered](ordered OrderedList[T], selector func(T) K) OrderedList[T] {
+	return ordered.ThenBy(By(selector))
+}
+
+// ThenByDescending breaks ties in an ordered list descending by the key selector
+// ordered: The ordered list to refine
+// selector: The function to extract the key from each element
+func ThenByDescending[T any, K cmp.Ordered](ordered OrderedList[T], selector func(T) K) OrderedList[T] {
+	return ordered.ThenBy(By(selector).Reverse())
+}
+
+// ThenBy breaks ties ascending by the given key
+// key: The key to sort by, usually created with By
+func (o OrderedList[T]) ThenBy(key SortKey[T]) OrderedList[T] {
+	return orderByKeys(o.List, append(slices.Clip(o.keys), key)...)
+}
+
+// ThenByDescending breaks ties descending by the given key
+// key: The key to sort by, usually created with By
+func (o OrderedList[T]) ThenByDescending(key SortKey[T]) OrderedList[T] {
+	return o.ThenBy(key.Reverse())
+}
+
+// ToList returns the sorted elements as a List
+func (o OrderedList[T]) ToList() List[T] {
+	return o.List
+}
+
+// OrderBy returns a copy of the list stably sorted ascending by the given key
+// key: The key to sort by, usually created with By
+func (l List[T]) OrderBy(key SortKey[T]) OrderedList[T] {
+	return orderByKeys(l, key)
+}
+
+// OrderByDescending returns a copy of the list stably sorted descending by the given key
+// key: The key to sort by, usually created with By
+func (l List[T]) OrderByDescending(key SortKey[T]) OrderedList[T] {
+	return orderByKeys(l, key.Reverse())
+}
+
+// CompareKeys combines sort keys into a single comparer that consults each key in turn until one breaks the tie
+// keys: The keys to compare by, in priority order
+func CompareKeys[T any](keys ...SortKey[T]) func(a T, b T) int {
+	return func(a T, b T) int {
+		for _, key := range keys {
+			if c := key(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+func orderByKeys[T any](items []T, keys ...SortKey[T]) OrderedList[T] {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, CompareKeys(keys...))
+	return OrderedList[T]{
+		List: sorted,
+		keys: keys,
+	}
+}
+
+// OrderByPath returns a copy of the slice stably sorted by an SQL-style order clause such as "Address.City asc, Age desc".
+// Each path is a dotted list of exported field names, followed through pointers and interfaces. The direction defaults to asc when omitted.
+// Fields must be ints, uints, floats, strings or time.Time; values behind a nil pointer or nil interface sort first.
+// Every path is resolved once per element before sorting. An error is returned if a non-nil value along a path through an
+// interface field does not have the field, or if the values a path reaches are not all of the same type
+// items: The slice to sort
+// order: The comma separated list of field paths and directions
+func OrderByPath[T any](items []T, order string) (OrderedList[T], error) {
+	terms, err := parseOrderClause[T](order)
+	if err != nil {
+		return OrderedList[T]{}, err
+	}
+
+	// values holds the resolved key values of item i at i*len(terms), so sorting never walks a path again
+	values := make([]reflect.Value, len(items)*len(terms))
+	source := reflect.ValueOf(items)
+	for j, term := range terms {
+		var first reflect.Type
+		for i := range items {
+			v, err := resolveOrderValue(source.Index(i), term.parts)
+			if err != nil {
+				return OrderedList[T]{}, err
+			}
+			if v.IsValid() {
+				if first == nil {
+					first = v.Type()
+				} else if v.Type() != first {
+					return OrderedList[T]{}, fmt.Errorf("field has values of different types: %s (%s and %s)", term.path, first, v.Type())
+				}
+			}
+			values[i*len(terms)+j] = v
+		}
+	}
+
+	indices := make([]int, len(items))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(a int, b int) int {
+		for j, term := range terms {
+			c := compareOrdered(values[a*len(terms)+j], values[b*len(terms)+j])
+			if term.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	sorted := make([]T, len(items))
+	for i, index := range indices {
+		sorted[i] = items[index]
+	}
+	keys := make([]SortKey[T], len(terms))
+	for j, term := range terms {
+		keys[j] = pathSortKey[T](term)
+	}
+	return OrderedList[T]{
+		List: sorted,
+		keys: keys,
+	}, nil
+}
+
+// OrderByPath returns a copy of the list stably sorted by an SQL-style order clause such as "Address.City asc, Age desc"
+// order: The comma separated list of field paths and directions
+func (l List[T]) OrderByPath(order string) (OrderedList[T], error) {
+	return OrderByPath(l, order)
+}
+
+// orderTerm is one field path of an order clause, split into its field names, and its direction
+type orderTerm struct {
+	path       string
+	parts      []string
+	descending bool
+}
+
+func parseOrderClause[T any](order string) ([]orderTerm, error) {
+	if strings.TrimSpace(order) == "" {
+		return nil, fmt.Errorf("order clause cannot be empty")
+	}
+
+	var terms []orderTerm
+	for _, term := range strings.Split(order, ",") {
+		fields := strings.Fields(term)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid order term: %q", strings.TrimSpace(term))
+		}
+
+		path := fields[0]
+		descending := false
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				descending = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction for %s: %s", path, fields[1])
+			}
+		}
+
+		parts := strings.Split(path, ".")
+		if err := validateOrderPath(reflect.TypeFor[T](), path, parts); err != nil {
+			return nil, err
+		}
+		terms = append(terms, orderTerm{path: path, parts: parts, descending: descending})
+	}
+
+	return terms, nil
+}
+
+// validateOrderPath walks the path through the type and checks that it ends at an exported, ordered field.
+// Interface types cannot be checked statically, so it stops at the first one and the path is checked as each element is resolved
+func validateOrderPath(t reflect.Type, path string, parts []string) error {
+	for _, part := range parts {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Interface {
+			return nil
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("field not found in path: %s", part)
+		}
+		field, ok := t.FieldByName(part)
+		if !ok {
+			return fmt.Errorf("field not found in path: %s", part)
+		}
+		if !field.IsExported() {
+			return fmt.Errorf("field is not exported: %s", part)
+		}
+		t = field.Type
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Interface && !isOrderedType(t) {
+		return fmt.Errorf("field is not comparable: %s (%s)", path, t)
+	}
+	return nil
+}
+
+func isOrderedType(t reflect.Type) bool {
+	if t == reflect.TypeFor[time.Time]() {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// pathSortKey compares two elements by resolving the path on each. OrderByPath sorts on values it resolved up front,
+// so this only runs when the result is refined with ThenBy
+func pathSortKey[T any](term orderTerm) SortKey[T] {
+	return func(a T, b T) int {
+		// The path was checked on every element when the list was sorted, so it resolves without errors
+		av, _ := resolveOrderValue(reflect.ValueOf(&a).Elem(), term.parts)
+		bv, _ := resolveOrderValue(reflect.ValueOf(&b).Elem(), term.parts)
+		if term.descending {
+			return compareOrdered(bv, av)
+		}
+		return compareOrdered(av, bv)
+	}
+}
+
+// resolveOrderValue resolves the field path on a value, unwrapping pointers and interfaces along the way.
+// It returns an invalid value when the path reaches a nil pointer or nil interface, and an error instead of panicking
+// when a value does not have the field, the field is unexported or the value it reaches is not ordered.
+// Get cannot be used here: it fails at a nil pointer, where an order clause sorts the element first, it does not look
+// through interfaces, and it boxes every value into an any
+func resolveOrderValue(v reflect.Value, parts []string) (reflect.Value, error) {
+	for _, part := range parts {
+		if v = indirectOrderValue(v); !v.IsValid() {
+			return reflect.Value{}, nil
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("field not found in path: %s (%s)", part, v.Type())
+		}
+		field, ok := v.Type().FieldByName(part)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field not found in path: %s (%s)", part, v.Type())
+		}
+		if !field.IsExported() {
+			return reflect.Value{}, fmt.Errorf("field is not exported: %s", part)
+		}
+		var err error
+		if v, err = v.FieldByIndexErr(field.Index); err != nil {
+			// The field is promoted through a nil embedded pointer
+			return reflect.Value{}, nil
+		}
+	}
+
+	if v = indirectOrderValue(v); !v.IsValid() {
+		return reflect.Value{}, nil
+	}
+	if !v.CanInterface() {
+		return reflect.Value{}, fmt.Errorf("field is not exported: %s", strings.Join(parts, "."))
+	}
+	if !isOrderedType(v.Type()) {
+		return reflect.Value{}, fmt.Errorf("field is not comparable: %s (%s)", strings.Join(parts, "."), v.Type())
+	}
+	if v.Type() == reflect.TypeFor[time.Time]() && !v.CanAddr() {
+		// Keep times addressable so compareOrdered can read them through a pointer without copying them into an any
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	return v, nil
+}
+
+// indirectOrderValue follows pointers and interfaces to the value they hold, returning an invalid value at a nil one
+func indirectOrderValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// compareOrdered compares two values of the same ordered type. Invalid values sort before everything else
+func compareOrdered(a reflect.Value, b reflect.Value) int {
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return -1
+	case !b.IsValid():
+		return 1
+	}
+
+	if a.Type() == reflect.TypeFor[time.Time]() {
+		return a.Addr().Interface().(*time.Time).Compare(*b.Addr().Interface().(*time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	default:
+		return cmp.Compare(a.String(), b.String())
+	}
+}
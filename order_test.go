@@ -0,0 +1,319 @@
+package ectolinq
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPerson struct {
+	First string
+	Last  string
+	Age   int
+}
+
+var orderPeople = []orderPerson{
+	{First: "Grace", Last: "Hopper", Age: 85},
+	{First: "Ada", Last: "Lovelace", Age: 36},
+	{First: "Alan", Last: "Turing", Age: 41},
+	{First: "Anne", Last: "Hopper", Age: 85},
+	{First: "Ada", Last: "Hopper", Age: 30},
+}
+
+func fullNames(items []orderPerson) []string {
+	return Map(items, func(p orderPerson) string { return p.First + " " + p.Last })
+}
+
+func TestOrderBy(t *testing.T) {
+	t.Run("Sort by a single key", func(t *testing.T) {
+		sorted := OrderBy(orderPeople, func(p orderPerson) int { return p.Age })
+		assert.Equal(t, []int{30, 36, 41, 85, 85}, Map(sorted.List, func(p orderPerson) int { return p.Age }))
+	})
+
+	t.Run("Sort is stable", func(t *testing.T) {
+		sorted := OrderBy(orderPeople, func(p orderPerson) string { return p.Last })
+		assert.Equal(t, []string{"Grace Hopper", "Anne Hopper", "Ada Hopper", "Ada Lovelace", "Alan Turing"}, fullNames(sorted.List))
+	})
+
+	t.Run("Sort descending", func(t *testing.T) {
+		sorted := OrderByDescending(orderPeople, func(p orderPerson) int { return p.Age })
+		assert.Equal(t, []string{"Grace Hopper", "Anne Hopper", "Alan Turing", "Ada Lovelace", "Ada Hopper"}, fullNames(sorted.List))
+	})
+
+	t.Run("Input is not modified", func(t *testing.T) {
+		items := []int{3, 1, 2}
+		sorted := OrderBy(items, func(n int) int { return n })
+		assert.Equal(t, List[int]{1, 2, 3}, sorted.ToList())
+		assert.Equal(t, []int{3, 1, 2}, items)
+	})
+
+	t.Run("Empty slice", func(t *testing.T) {
+		sorted := OrderBy([]int{}, func(n int) int { return n })
+		assert.Empty(t, sorted.List)
+	})
+}
+
+func TestThenBy(t *testing.T) {
+	t.Run("Sort by last name, then first name, then age", func(t *testing.T) {
+		sorted := ThenBy(ThenBy(OrderBy(orderPeople,
+			func(p orderPerson) string { return p.Last }),
+			func(p orderPerson) string { return p.First }),
+			func(p orderPerson) int { return p.Age })
+		assert.Equal(t, []string{"Ada Hopper", "Anne Hopper", "Grace Hopper", "Ada Lovelace", "Alan Turing"}, fullNames(sorted.List))
+	})
+
+	t.Run("ThenByDescending", func(t *testing.T) {
+		sorted := ThenByDescending(OrderBy(orderPeople, func(p orderPerson) string { return p.Last }), func(p orderPerson) int { return p.Age })
+		assert.Equal(t, []string{"Grace Hopper", "Anne Hopper", "Ada Hopper", "Ada Lovelace", "Alan Turing"}, fullNames(sorted.List))
+	})
+
+	t.Run("Branching does not share keys", func(t *testing.T) {
+		base := OrderBy(orderPeople, func(p orderPerson) string { return p.Last })
+		byFirst := ThenBy(base, func(p orderPerson) string { return p.First })
+		byAge := ThenByDescending(base, func(p orderPerson) int { return p.Age })
+		assert.Equal(t, "Ada Hopper", fullNames(byFirst.List)[0])
+		assert.Equal(t, "Grace Hopper", fullNames(byAge.List)[0])
+	})
+}
+
+func TestListOrderBy(t *testing.T) {
+	t.Run("Chain on a list", func(t *testing.T) {
+		list := ToList(orderPeople)
+		sorted := list.
+			OrderBy(By(func(p orderPerson) string { return p.Last })).
+			ThenByDescending(By(func(p orderPerson) int { return p.Age })).
+			ThenBy(By(func(p orderPerson) string { return p.First }))
+		assert.Equal(t, []string{"Anne Hopper", "Grace Hopper", "Ada Hopper", "Ada Lovelace", "Alan Turing"}, fullNames(sorted.List))
+		assert.Equal(t, orderPeople[0], list[0], "Source list should not be modified")
+	})
+
+	t.Run("OrderByDescending on a list", func(t *testing.T) {
+		sorted := ToList([]string{"b", "c", "a"}).OrderByDescending(By(func(s string) string { return s }))
+		assert.Equal(t, List[string]{"c", "b", "a"}, sorted.ToList())
+	})
+
+	t.Run("Ordered list keeps list methods", func(t *testing.T) {
+		sorted := ToList([]int{5, 3, 4}).OrderBy(By(func(n int) int { return n }))
+		assert.Equal(t, 3, sorted.First())
+		assert.Equal(t, List[int]{3, 4}, sorted.Take(2))
+	})
+}
+
+func TestCompareKeys(t *testing.T) {
+	compare := CompareKeys(By(func(p orderPerson) string { return p.Last }), By(func(p orderPerson) int { return p.Age }).Reverse())
+	assert.Negative(t, compare(orderPeople[0], orderPeople[1]))
+	assert.Positive(t, compare(orderPeople[4], orderPeople[0]))
+	assert.Zero(t, compare(orderPeople[0], orderPeople[3]))
+}
+
+type pathAddress struct {
+	City string
+}
+
+type pathPerson struct {
+	Name     string
+	Age      int
+	Score    float64
+	Born     time.Time
+	Address  pathAddress
+	Manager  *pathPerson
+	Tags     []string
+	Verified bool
+}
+
+func TestOrderByPath(t *testing.T) {
+	now := time.Now()
+	items := []pathPerson{
+		{Name: "Ada", Age: 36, Score: 2.5, Born: now.Add(2 * time.Hour), Address: pathAddress{City: "London"}},
+		{Name: "Grace", Age: 85, Score: 1.5, Born: now, Address: pathAddress{City: "Arlington"}},
+		{Name: "Alan", Age: 41, Score: 3.5, Born: now.Add(time.Hour), Address: pathAddress{City: "London"}},
+	}
+	items[1].Manager = &items[0]
+	names := func(sorted OrderedList[pathPerson]) []string {
+		return Map(sorted.List, func(p pathPerson) string { return p.Name })
+	}
+
+	t.Run("Nested path with directions", func(t *testing.T) {
+		sorted, err := OrderByPath(items, "Address.City asc, Age desc")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Grace", "Alan", "Ada"}, names(sorted))
+	})
+
+	t.Run("Direction defaults to asc and is case-insensitive", func(t *testing.T) {
+		sorted, err := OrderByPath(items, "Score")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Grace", "Ada", "Alan"}, names(sorted))
+
+		sorted, err = OrderByPath(items, "  Score   DESC ")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alan", "Ada", "Grace"}, names(sorted))
+	})
+
+	t.Run("time.Time fields", func(t *testing.T) {
+		sorted, err := OrderByPath(items, "Born desc")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ada", "Alan", "Grace"}, names(sorted))
+	})
+
+	t.Run("Nil pointers sort first", func(t *testing.T) {
+		sorted, err := OrderByPath(items, "Manager.Name desc, Name")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Grace", "Ada", "Alan"}, names(sorted))
+	})
+
+	t.Run("Slice of pointers", func(t *testing.T) {
+		pointers := []*pathPerson{&items[0], &items[1], &items[2]}
+		sorted, err := OrderByPath(pointers, "Age")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", sorted.First().Name)
+	})
+
+	t.Run("Can be refined with ThenBy", func(t *testing.T) {
+		sorted, err := ToList(items).OrderByPath("Address.City")
+		require.NoError(t, err)
+		sorted = ThenByDescending(sorted, func(p pathPerson) string { return p.Name })
+		assert.Equal(t, []string{"Grace", "Alan", "Ada"}, names(sorted))
+
+		sorted, err = OrderByPath(items, "Address.City desc")
+		require.NoError(t, err)
+		sorted = ThenBy(sorted, func(p pathPerson) string { return p.Name })
+		assert.Equal(t, []string{"Ada", "Alan", "Grace"}, names(sorted))
+	})
+
+	t.Run("Input is not modified", func(t *testing.T) {
+		_, err := OrderByPath(items, "Name desc")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", items[0].Name)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, order := range []string{
+			"",
+			"Unknown",
+			"Address.Unknown",
+			"Name.First",
+			"Tags",
+			"Verified",
+			"Address",
+			"Age sideways",
+			"Age desc extra",
+			"Age,,Name",
+		} {
+			_, err := OrderByPath(items, order)
+			assert.Error(t, err, "Expected an error for %q", order)
+		}
+	})
+	t.Run("Unexported field returns an error", func(t *testing.T) {
+		type person struct {
+			Name string
+			age  int
+		}
+		people := []person{{Name: "Ada", age: 36}, {Name: "Alan", age: 41}}
+		assert.NotPanics(t, func() {
+			_, err := OrderByPath(people, "age")
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("Path through an interface is resolved per element", func(t *testing.T) {
+		type meta struct{ Name string }
+		type tagged struct {
+			ID   int
+			Meta any
+		}
+		elements := []tagged{
+			{ID: 1, Meta: meta{Name: "b"}},
+			{ID: 3, Meta: &meta{Name: "a"}},
+			{ID: 4},
+			{ID: 5, Meta: (*meta)(nil)},
+		}
+		sorted, err := OrderByPath(elements, "Meta.Name")
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5, 3, 1}, Map(sorted.List, func(item tagged) int { return item.ID }))
+
+		sorted, err = OrderByPath(elements, "Meta")
+		require.Error(t, err, "A struct held by an interface is not comparable")
+		assert.Empty(t, sorted.List)
+	})
+
+	t.Run("Path through an interface returns an error when an element cannot resolve it", func(t *testing.T) {
+		type named struct{ Name string }
+		type numbered struct{ Name int }
+		type tagged struct{ Meta any }
+		for name, elements := range map[string][]tagged{
+			"not a struct":    {{Meta: named{Name: "a"}}, {Meta: "not a struct"}},
+			"missing field":   {{Meta: named{Name: "a"}}, {Meta: struct{ Other int }{}}},
+			"different types": {{Meta: named{Name: "a"}}, {Meta: numbered{Name: 1}}},
+			"unordered value": {{Meta: struct{ Name []int }{}}},
+		} {
+			assert.NotPanics(t, func() {
+				_, err := OrderByPath(elements, "Meta.Name")
+				assert.Error(t, err, "Expected an error for %s", name)
+			})
+		}
+
+		_, err := OrderByPath([]tagged{{Meta: named{Name: "a"}}}, "Meta.Foo")
+		assert.Error(t, err)
+	})
+
+	t.Run("Interface values must all have the same type", func(t *testing.T) {
+		type cell struct{ Value any }
+		sorted, err := OrderByPath([]cell{{Value: 2}, {}, {Value: 1}}, "Value")
+		require.NoError(t, err)
+		assert.Equal(t, []any{nil, 1, 2}, Map(sorted.List, func(c cell) any { return c.Value }))
+
+		_, err = OrderByPath([]cell{{Value: 2}, {Value: "1"}}, "Value")
+		assert.Error(t, err)
+		_, err = OrderByPath([]cell{{Value: 2}, {Value: int64(1)}}, "Value")
+		assert.Error(t, err)
+	})
+
+	t.Run("Nil embedded pointer sorts first", func(t *testing.T) {
+		type Base struct{ Rank int }
+		type ranked struct {
+			*Base
+			Name string
+		}
+		people := []ranked{
+			{Base: &Base{Rank: 2}, Name: "Ada"},
+			{Name: "Alan"},
+			{Base: &Base{Rank: 1}, Name: "Grace"},
+			{Name: "Edsger"},
+		}
+		assert.NotPanics(t, func() {
+			sorted, err := OrderByPath(people, "Rank")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Alan", "Edsger", "Grace", "Ada"}, Map(sorted.List, func(p ranked) string { return p.Name }))
+
+			sorted, err = OrderByPath(people, "Rank desc")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Ada", "Grace", "Alan", "Edsger"}, Map(sorted.List, func(p ranked) string { return p.Name }))
+		})
+	})
+
+}
+
+func BenchmarkOrderByPath(b *testing.B) {
+	items := make([]pathPerson, 10000)
+	for i := range items {
+		items[i] = pathPerson{Name: fmt.Sprint(i), Age: i % 90, Address: pathAddress{City: fmt.Sprint("city", i%50)}}
+	}
+
+	b.Run("OrderByPath", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := OrderByPath(items, "Address.City asc, Age desc"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("OrderBy", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			ThenByDescending(OrderBy(items, func(p pathPerson) string { return p.Address.City }), func(p pathPerson) int { return p.Age })
+		}
+	})
+}
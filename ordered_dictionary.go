@@ -0,0 +1,445 @@
+package ectolinq
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// OrderedDictionary is a dictionary that remembers the order keys were inserted.
+// Lookups, inserts and removals are O(1); positional access with At is O(n)
+type OrderedDictionary[K comparable, V any] struct {
+	entries map[K]*orderedEntry[K, V]
+	head    *orderedEntry[K, V]
+	tail    *orderedEntry[K, V]
+}
+
+type orderedEntry[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *orderedEntry[K, V]
+	next  *orderedEntry[K, V]
+}
+
+// NewOrderedDictionary creates a new ordered dictionary
+func NewOrderedDictionary[K comparable, V any]() *OrderedDictionary[K, V] {
+	return &OrderedDictionary[K, V]{
+		entries: make(map[K]*orderedEntry[K, V]),
+	}
+}
+
+// NewOrderedDictionaryWithCapacity creates a new ordered dictionary with an initial capacity
+// capacity: The number of entries to allocate space for
+func NewOrderedDictionaryWithCapacity[K comparable, V any](capacity int) *OrderedDictionary[K, V] {
+	return &OrderedDictionary[K, V]{
+		entries: make(map[K]*orderedEntry[K, V], capacity),
+	}
+}
+
+// ToOrderedDictionary creates a new ordered dictionary from a slice of entries, keeping their order
+// entries: The entries to create the dictionary from
+func ToOrderedDictionary[K comparable, V any](entries []MapEntry[K, V]) *OrderedDictionary[K, V] {
+	d := NewOrderedDictionaryWithCapacity[K, V](len(entries))
+	for _, entry := range entries {
+		d.Set(entry.Key, entry.Value)
+	}
+	return d
+}
+
+// Get returns the value in the dictionary for the given key
+// key: The key to get the value for
+func (d *OrderedDictionary[K, V]) Get(key K) (V, bool) {
+	if entry, ok := d.entries[key]; ok {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets the value in the dictionary for the given key. New keys are added at the back; existing keys keep their position
+// key: The key to set the value for
+// value: The value to set
+func (d *OrderedDictionary[K, V]) Set(key K, value V) {
+	if entry, ok := d.entries[key]; ok {
+		entry.value = value
+		return
+	}
+	if d.entries == nil {
+		d.entries = make(map[K]*orderedEntry[K, V])
+	}
+	entry := &orderedEntry[K, V]{key: key, value: value}
+	d.entries[key] = entry
+	d.pushBack(entry)
+}
+
+// Keys returns the keys in the dictionary in insertion order
+func (d *OrderedDictionary[K, V]) Keys() []K {
+	keys := make([]K, 0, len(d.entries))
+	for entry := d.head; entry != nil; entry = entry.next {
+		keys = append(keys, entry.key)
+	}
+	return keys
+}
+
+// ContainsKey returns if the dictionary contains the given key
+// key: The key to check for
+func (d *OrderedDictionary[K, V]) ContainsKey(key K) bool {
+	_, ok := d.entries[key]
+	return ok
+}
+
+// ContainsValue returns if the dictionary contains the given value
+// value: The value to check for
+func (d *OrderedDictionary[K, V]) ContainsValue(value V) bool {
+	return d.ContainsWhere(func(_ K, v V) bool {
+		return Equals(v, value)
+	})
+}
+
+// ContainsWhere returns if the dictionary contains a value that satisfies the given predicate
+// fn: The predicate to check for
+func (d *OrderedDictionary[K, V]) ContainsWhere(fn func(K, V) bool) bool {
+	for entry := d.head; entry != nil; entry = entry.next {
+		if fn(entry.key, entry.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the value in the dictionary for the given key
+// key: The key to remove the value for
+func (d *OrderedDictionary[K, V]) Remove(key K) {
+	if entry, ok := d.entries[key]; ok {
+		d.unlink(entry)
+		delete(d.entries, key)
+	}
+}
+
+// RemoveValue removes the given value from the dictionary
+// value: The value to remove
+func (d *OrderedDictionary[K, V]) RemoveValue(value V) {
+	d.RemoveWhere(func(_ K, v V) bool {
+		return Equals(v, value)
+	})
+}
+
+// RemoveWhere removes the value in the dictionary that satisfies the given predicate
+// fn: The predicate to check for
+func (d *OrderedDictionary[K, V]) RemoveWhere(fn func(K, V) bool) {
+	for entry := d.head; entry != nil; {
+		next := entry.next
+		if fn(entry.key, entry.value) {
+			d.Remove(entry.key)
+		}
+		entry = next
+	}
+}
+
+// Merge merges the given dictionaries into the dictionary in their order. Existing keys keep their position
+// dicts: The dictionaries to merge
+func (d *OrderedDictionary[K, V]) Merge(dicts ...OrderedDictionary[K, V]) *OrderedDictionary[K, V] {
+	for _, dict := range dicts {
+		for entry := dict.head; entry != nil; entry = entry.next {
+			d.Set(entry.key, entry.value)
+		}
+	}
+	return d
+}
+
+// MergeMaps merges the given maps into the dictionary. New keys from a single map are added in map iteration order
+// maps: The maps to merge
+func (d *OrderedDictionary[K, V]) MergeMaps(maps ...map[K]V) {
+	for _, m := range maps {
+		for key, value := range m {
+			d.Set(key, value)
+		}
+	}
+}
+
+// Clear removes all values from the dictionary
+func (d *OrderedDictionary[K, V]) Clear() {
+	d.entries = make(map[K]*orderedEntry[K, V])
+	d.head = nil
+	d.tail = nil
+}
+
+// Count returns the number of values in the dictionary
+func (d *OrderedDictionary[K, V]) Count() int {
+	return len(d.entries)
+}
+
+// ToArray returns the values in the dictionary as an array in insertion order
+func (d *OrderedDictionary[K, V]) ToArray() []V {
+	values := make([]V, 0, len(d.entries))
+	for entry := d.head; entry != nil; entry = entry.next {
+		values = append(values, entry.value)
+	}
+	return values
+}
+
+// ToList returns the values in the dictionary as a list in insertion order
+func (d *OrderedDictionary[K, V]) ToList() List[V] {
+	return d.ToArray()
+}
+
+// ToMap returns the dictionary as a new map
+func (d *OrderedDictionary[K, V]) ToMap() map[K]V {
+	m := make(map[K]V, len(d.entries))
+	for key, entry := range d.entries {
+		m[key] = entry.value
+	}
+	return m
+}
+
+// Values returns the values in the dictionary in insertion order
+func (d *OrderedDictionary[K, V]) Values() []V {
+	return d.ToArray()
+}
+
+// Entries returns the key-value pairs in the dictionary in insertion order
+func (d *OrderedDictionary[K, V]) Entries() []MapEntry[K, V] {
+	entries := make([]MapEntry[K, V], 0, len(d.entries))
+	for entry := d.head; entry != nil; entry = entry.next {
+		entries = append(entries, MapEntry[K, V]{Key: entry.key, Value: entry.value})
+	}
+	return entries
+}
+
+// All returns an iterator over the key-value pairs in insertion order
+func (d *OrderedDictionary[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for entry := d.head; entry != nil; entry = entry.next {
+			if !yield(entry.key, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the key-value pairs in reverse insertion order
+func (d *OrderedDictionary[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for entry := d.tail; entry != nil; entry = entry.prev {
+			if !yield(entry.key, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// At returns the key and value at the given position and whether the position is in range.
+// This walks the entries from the nearest end, so it is O(n)
+// index: The zero-based position
+func (d *OrderedDictionary[K, V]) At(index int) (K, V, bool) {
+	if index < 0 || index >= len(d.entries) {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	var entry *orderedEntry[K, V]
+	if index < len(d.entries)/2 {
+		entry = d.head
+		for i := 0; i < index; i++ {
+			entry = entry.next
+		}
+	} else {
+		entry = d.tail
+		for i := len(d.entries) - 1; i > index; i-- {
+			entry = entry.prev
+		}
+	}
+	return entry.key, entry.value, true
+}
+
+// IndexOf returns the position of the given key, or -1 if it is not present. This is O(n)
+// key: The key to locate
+func (d *OrderedDictionary[K, V]) IndexOf(key K) int {
+	if _, ok := d.entries[key]; !ok {
+		return -1
+	}
+	index := 0
+	for entry := d.head; entry.key != key; entry = entry.next {
+		index++
+	}
+	return index
+}
+
+// MoveToFront moves the given key to the front of the order. It returns false if the key is not present
+// key: The key to move
+func (d *OrderedDictionary[K, V]) MoveToFront(key K) bool {
+	entry, ok := d.entries[key]
+	if !ok {
+		return false
+	}
+	d.unlink(entry)
+	d.pushFront(entry)
+	return true
+}
+
+// MoveToBack moves the given key to the back of the order. It returns false if the key is not present
+// key: The key to move
+func (d *OrderedDictionary[K, V]) MoveToBack(key K) bool {
+	entry, ok := d.entries[key]
+	if !ok {
+		return false
+	}
+	d.unlink(entry)
+	d.pushBack(entry)
+	return true
+}
+
+// MarshalJSON encodes the dictionary as a JSON object whose members are in insertion order.
+// Keys follow the encoding/json rules for map keys: string kinds, encoding.TextMarshaler and integer kinds
+func (d OrderedDictionary[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for entry := d.head; entry != nil; entry = entry.next {
+		if entry != d.head {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSONKey(entry.key)
+		if err != nil {
+			return nil, err
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(entry.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the dictionary, keeping the order of its members.
+// Existing entries are cleared first. Like encoding/json, a JSON null leaves the dictionary unchanged
+func (d *OrderedDictionary[K, V]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	d.Clear()
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var key K
+		if err := unmarshalJSONKey(token.(string), &key); err != nil {
+			return err
+		}
+		var value V
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		d.Set(key, value)
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+func (d *OrderedDictionary[K, V]) pushBack(entry *orderedEntry[K, V]) {
+	entry.prev = d.tail
+	entry.next = nil
+	if d.tail != nil {
+		d.tail.next = entry
+	} else {
+		d.head = entry
+	}
+	d.tail = entry
+}
+
+func (d *OrderedDictionary[K, V]) pushFront(entry *orderedEntry[K, V]) {
+	entry.prev = nil
+	entry.next = d.head
+	if d.head != nil {
+		d.head.prev = entry
+	} else {
+		d.tail = entry
+	}
+	d.head = entry
+}
+
+func (d *OrderedDictionary[K, V]) unlink(entry *orderedEntry[K, V]) {
+	if entry.prev != nil {
+		entry.prev.next = entry.next
+	} else {
+		d.head = entry.next
+	}
+	if entry.next != nil {
+		entry.next.prev = entry.prev
+	} else {
+		d.tail = entry.prev
+	}
+	entry.prev = nil
+	entry.next = nil
+}
+
+// marshalJSONKey converts a map key to its JSON object member name
+func marshalJSONKey(key any) (string, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if marshaler, ok := key.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported JSON key type: %T", key)
+}
+
+// unmarshalJSONKey parses a JSON object member name into a map key
+func unmarshalJSONKey(text string, key any) error {
+	v := reflect.ValueOf(key).Elem()
+	if v.Kind() == reflect.String {
+		v.SetString(text)
+		return nil
+	}
+	if unmarshaler, ok := key.(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid JSON key %q: %w", text, err)
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid JSON key %q: %w", text, err)
+		}
+		v.SetUint(n)
+		return nil
+	}
+	return fmt.Errorf("unsupported JSON key type: %s", v.Type())
+}
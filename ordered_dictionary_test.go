@@ -0,0 +1,225 @@
+package ectolinq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrderedDictionary() *OrderedDictionary[string, int] {
+	d := NewOrderedDictionary[string, int]()
+	d.Set("c", 3)
+	d.Set("a", 1)
+	d.Set("b", 2)
+	return d
+}
+
+func TestNewOrderedDictionary(t *testing.T) {
+	d := NewOrderedDictionary[string, int]()
+	require.NotNil(t, d, "NewOrderedDictionary should not return nil")
+	assert.Equal(t, 0, d.Count(), "New dictionary should be empty")
+
+	d = NewOrderedDictionaryWithCapacity[string, int](10)
+	require.NotNil(t, d, "NewOrderedDictionaryWithCapacity should not return nil")
+}
+
+func TestToOrderedDictionary(t *testing.T) {
+	d := ToOrderedDictionary([]MapEntry[string, int]{{Key: "z", Value: 26}, {Key: "a", Value: 1}})
+	assert.Equal(t, []string{"z", "a"}, d.Keys(), "ToOrderedDictionary should keep entry order")
+}
+
+func TestOrderedDictionaryGetSet(t *testing.T) {
+	d := newTestOrderedDictionary()
+	value, ok := d.Get("a")
+	assert.True(t, ok, "Get should return true for existing key")
+	assert.Equal(t, 1, value, "Get should return correct value")
+
+	_, ok = d.Get("missing")
+	assert.False(t, ok, "Get should return false for nonexistent key")
+
+	d.Set("c", 30)
+	assert.Equal(t, []string{"c", "a", "b"}, d.Keys(), "Updating a key should keep its position")
+	assert.Equal(t, []int{30, 1, 2}, d.Values(), "Updating a key should change its value")
+}
+
+func TestOrderedDictionaryZeroValue(t *testing.T) {
+	var d OrderedDictionary[string, int]
+	d.Set("a", 1)
+	assert.Equal(t, 1, d.Count(), "Zero value should be usable")
+}
+
+func TestOrderedDictionaryKeysValuesInOrder(t *testing.T) {
+	d := newTestOrderedDictionary()
+	assert.Equal(t, []string{"c", "a", "b"}, d.Keys(), "Keys should be in insertion order")
+	assert.Equal(t, []int{3, 1, 2}, d.ToArray(), "ToArray should be in insertion order")
+	assert.Equal(t, List[int]{3, 1, 2}, d.ToList(), "ToList should be in insertion order")
+	assert.Equal(t, []MapEntry[string, int]{{"c", 3}, {"a", 1}, {"b", 2}}, d.Entries(), "Entries should be in insertion order")
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, d.ToMap(), "ToMap should contain every entry")
+}
+
+func TestOrderedDictionaryContains(t *testing.T) {
+	d := newTestOrderedDictionary()
+	assert.True(t, d.ContainsKey("a"), "ContainsKey should return true for existing key")
+	assert.False(t, d.ContainsKey("z"), "ContainsKey should return false for missing key")
+	assert.True(t, d.ContainsValue(2), "ContainsValue should return true for existing value")
+	assert.True(t, d.ContainsWhere(func(k string, v int) bool { return k == "b" && v == 2 }), "ContainsWhere should return true for existing condition")
+}
+
+func TestOrderedDictionaryRemove(t *testing.T) {
+	t.Run("Remove middle, head and tail", func(t *testing.T) {
+		d := newTestOrderedDictionary()
+		d.Remove("a")
+		assert.Equal(t, []string{"c", "b"}, d.Keys())
+		d.Remove("c")
+		assert.Equal(t, []string{"b"}, d.Keys())
+		d.Remove("b")
+		assert.Empty(t, d.Keys())
+		d.Set("d", 4)
+		assert.Equal(t, []string{"d"}, d.Keys(), "Dictionary should be reusable after removing everything")
+	})
+
+	t.Run("Remove missing key", func(t *testing.T) {
+		d := newTestOrderedDictionary()
+		d.Remove("missing")
+		assert.Equal(t, 3, d.Count())
+	})
+
+	t.Run("RemoveValue and RemoveWhere", func(t *testing.T) {
+		d := newTestOrderedDictionary()
+		d.RemoveValue(1)
+		assert.Equal(t, []string{"c", "b"}, d.Keys())
+		d.RemoveWhere(func(_ string, v int) bool { return v > 1 })
+		assert.Equal(t, 0, d.Count())
+	})
+}
+
+func TestOrderedDictionaryMerge(t *testing.T) {
+	d := newTestOrderedDictionary()
+	other := ToOrderedDictionary([]MapEntry[string, int]{{"a", 10}, {"d", 4}})
+	d.Merge(*other)
+	assert.Equal(t, []string{"c", "a", "b", "d"}, d.Keys(), "Merge should append new keys and keep existing positions")
+	assert.Equal(t, []int{3, 10, 2, 4}, d.Values())
+
+	d.MergeMaps(map[string]int{"e": 5})
+	assert.Equal(t, "e", d.Keys()[4], "MergeMaps should append new keys")
+}
+
+func TestOrderedDictionaryClear(t *testing.T) {
+	d := newTestOrderedDictionary()
+	d.Clear()
+	assert.Equal(t, 0, d.Count(), "Clear should remove all elements")
+	assert.Empty(t, d.Keys())
+}
+
+func TestOrderedDictionaryMove(t *testing.T) {
+	d := newTestOrderedDictionary()
+	assert.True(t, d.MoveToFront("b"))
+	assert.Equal(t, []string{"b", "c", "a"}, d.Keys())
+	assert.True(t, d.MoveToBack("b"))
+	assert.Equal(t, []string{"c", "a", "b"}, d.Keys())
+	assert.True(t, d.MoveToBack("c"))
+	assert.Equal(t, []string{"a", "b", "c"}, d.Keys())
+	assert.False(t, d.MoveToFront("missing"))
+}
+
+func TestOrderedDictionaryAt(t *testing.T) {
+	d := newTestOrderedDictionary()
+	d.Set("d", 4)
+	for index, expected := range []string{"c", "a", "b", "d"} {
+		key, _, ok := d.At(index)
+		assert.True(t, ok)
+		assert.Equal(t, expected, key, "At should return the key at position %d", index)
+		assert.Equal(t, index, d.IndexOf(expected))
+	}
+	_, _, ok := d.At(4)
+	assert.False(t, ok, "At should return false when out of range")
+	_, _, ok = d.At(-1)
+	assert.False(t, ok, "At should return false for negative positions")
+	assert.Equal(t, -1, d.IndexOf("missing"))
+}
+
+func TestOrderedDictionaryIterators(t *testing.T) {
+	d := newTestOrderedDictionary()
+	var keys []string
+	for key := range d.All() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, keys)
+
+	keys = nil
+	for key := range d.Backward() {
+		keys = append(keys, key)
+		if key == "a" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"b", "a"}, keys)
+}
+
+func TestOrderedDictionaryJSON(t *testing.T) {
+	t.Run("Marshal preserves order", func(t *testing.T) {
+		data, err := json.Marshal(newTestOrderedDictionary())
+		require.NoError(t, err)
+		assert.Equal(t, `{"c":3,"a":1,"b":2}`, string(data))
+	})
+
+	t.Run("Marshal as a struct field", func(t *testing.T) {
+		payload := struct {
+			Items OrderedDictionary[int, string] `json:"items"`
+		}{}
+		payload.Items.Set(2, "two")
+		payload.Items.Set(1, "one")
+		data, err := json.Marshal(payload)
+		require.NoError(t, err)
+		assert.Equal(t, `{"items":{"2":"two","1":"one"}}`, string(data))
+	})
+
+	t.Run("Marshal empty", func(t *testing.T) {
+		data, err := json.Marshal(NewOrderedDictionary[string, int]())
+		require.NoError(t, err)
+		assert.Equal(t, `{}`, string(data))
+	})
+
+	t.Run("Unmarshal preserves order", func(t *testing.T) {
+		d := NewOrderedDictionary[string, []int]()
+		err := json.Unmarshal([]byte(`{"z":[1],"y":[2,3],"x":[]}`), d)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"z", "y", "x"}, d.Keys())
+		assert.Equal(t, [][]int{{1}, {2, 3}, {}}, d.Values())
+	})
+
+	t.Run("Unmarshal integer keys", func(t *testing.T) {
+		var d OrderedDictionary[int, string]
+		require.NoError(t, json.Unmarshal([]byte(`{"10":"ten","2":"two"}`), &d))
+		assert.Equal(t, []int{10, 2}, d.Keys())
+	})
+
+	t.Run("Unmarshal null leaves the dictionary unchanged", func(t *testing.T) {
+		d := NewOrderedDictionary[string, int]()
+		d.Set("a", 1)
+		require.NoError(t, json.Unmarshal([]byte(`null`), d))
+		assert.Equal(t, []string{"a"}, d.Keys())
+
+		var holder struct {
+			Dict *OrderedDictionary[string, int]
+		}
+		require.NoError(t, json.Unmarshal([]byte(`{"Dict":null}`), &holder))
+		assert.Nil(t, holder.Dict)
+	})
+
+	t.Run("Unmarshal errors", func(t *testing.T) {
+		var d OrderedDictionary[int, string]
+		assert.Error(t, json.Unmarshal([]byte(`[1,2]`), &d))
+		assert.Error(t, json.Unmarshal([]byte(`{"x":"ten"}`), &d))
+		assert.Error(t, json.Unmarshal([]byte(`{"1":2}`), &d))
+	})
+
+	t.Run("Unsupported key type", func(t *testing.T) {
+		d := NewOrderedDictionary[float64, int]()
+		d.Set(1.5, 1)
+		_, err := json.Marshal(d)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,287 @@
+package ectolinq
+
+import "cmp"
+
+// PriorityQueue is a binary heap that always pops the item with the highest priority first.
+// Priority is defined by a less function: the queue pops an item a before b when less(a, b) is true
+type PriorityQueue[T any] struct {
+	items []*PriorityItem[T]
+	less  func(a T, b T) bool
+}
+
+// PriorityItem is a handle to an item in a PriorityQueue. It is returned by Push and used to Update or Remove the item
+type PriorityItem[T any] struct {
+	value T
+	index int
+	queue *PriorityQueue[T]
+}
+
+// Value returns the item the handle refers to
+func (p *PriorityItem[T]) Value() T {
+	return p.value
+}
+
+// NewPriorityQueue creates a new priority queue that pops a before b when less(a, b) is true
+// less: The function that defines the priority order
+func NewPriorityQueue[T any](less func(a T, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		less: less,
+	}
+}
+
+// NewMinPriorityQueue creates a new priority queue that pops the smallest item first
+func NewMinPriorityQueue[T cmp.Ordered]() *PriorityQueue[T] {
+	return NewPriorityQueue(cmp.Less[T])
+}
+
+// NewMaxPriorityQueue creates a new priority queue that pops the largest item first
+func NewMaxPriorityQueue[T cmp.Ordered]() *PriorityQueue[T] {
+	return NewPriorityQueue(func(a T, b T) bool {
+		return cmp.Less(b, a)
+	})
+}
+
+// NewMinPriorityQueueBy creates a new priority queue that pops the item with the smallest key first
+// selector: The function to extract the priority key from each item
+func NewMinPriorityQueueBy[T any, K cmp.Ordered](selector func(T) K) *PriorityQueue[T] {
+	return NewPriorityQueue(func(a T, b T) bool {
+		return cmp.Less(selector(a), selector(b))
+	})
+}
+
+// NewMaxPriorityQueueBy creates a new priority queue that pops the item with the largest key first
+// selector: The function to extract the priority key from each item
+func NewMaxPriorityQueueBy[T any, K cmp.Ordered](selector func(T) K) *PriorityQueue[T] {
+	return NewPriorityQueue(func(a T, b T) bool {
+		return cmp.Less(selector(b), selector(a))
+	})
+}
+
+// Push adds an item to the queue and returns a handle to it
+// item: The item to add
+func (pq *PriorityQueue[T]) Push(item T) *PriorityItem[T] {
+	handle := &PriorityItem[T]{value: item, index: len(pq.items), queue: pq}
+	pq.items = append(pq.items, handle)
+	pq.up(handle.index)
+	return handle
+}
+
+// Pop removes and returns the item with the highest priority, or false if the queue is empty
+func (pq *PriorityQueue[T]) Pop() (T, bool) {
+	if len(pq.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return pq.removeAt(0), true
+}
+
+// Peek returns the item with the highest priority without removing it, or false if the queue is empty
+func (pq *PriorityQueue[T]) Peek() (T, bool) {
+	if len(pq.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return pq.items[0].value, true
+}
+
+// Update replaces the item a handle refers to and restores the heap order.
+// It returns false if the handle has already been popped or removed, or belongs to another queue
+// handle: The handle returned by Push
+// item: The new item
+func (pq *PriorityQueue[T]) Update(handle *PriorityItem[T], item T) bool {
+	if !pq.owns(handle) {
+		return false
+	}
+	handle.value = item
+	pq.fix(handle.index)
+	return true
+}
+
+// Fix restores the heap order after the priority of the item a handle refers to has changed in place,
+// for example when the queue holds pointers. It returns false if the handle is no longer in this queue
+// handle: The handle returned by Push
+func (pq *PriorityQueue[T]) Fix(handle *PriorityItem[T]) bool {
+	if !pq.owns(handle) {
+		return false
+	}
+	pq.fix(handle.index)
+	return true
+}
+
+// Remove removes the item a handle refers to and returns it, or false if the handle is no longer in this queue
+// handle: The handle returned by Push
+func (pq *PriorityQueue[T]) Remove(handle *PriorityItem[T]) (T, bool) {
+	if !pq.owns(handle) {
+		var zero T
+		return zero, false
+	}
+	return pq.removeAt(handle.index), true
+}
+
+// Count returns the number of items in the queue
+func (pq *PriorityQueue[T]) Count() int {
+	return len(pq.items)
+}
+
+// Clear removes all items from the queue. Existing handles become invalid
+func (pq *PriorityQueue[T]) Clear() {
+	for _, handle := range pq.items {
+		handle.index = -1
+		handle.queue = nil
+	}
+	pq.items = nil
+}
+
+// Contains returns if the queue contains the given item
+// item: The item to check for
+func (pq *PriorityQueue[T]) Contains(item T) bool {
+	return pq.ContainsWhere(func(other T) bool {
+		return Equals(other, item)
+	})
+}
+
+// ContainsWhere returns if the queue contains an item that satisfies the given predicate
+// fn: The predicate to check for
+func (pq *PriorityQueue[T]) ContainsWhere(fn func(T) bool) bool {
+	for _, handle := range pq.items {
+		if fn(handle.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToArray returns the items in the queue in heap order, which is not sorted
+func (pq *PriorityQueue[T]) ToArray() []T {
+	values := make([]T, len(pq.items))
+	for i, handle := range pq.items {
+		values[i] = handle.value
+	}
+	return values
+}
+
+// ToList returns the items in the queue as a list in heap order, which is not sorted
+func (pq *PriorityQueue[T]) ToList() List[T] {
+	return pq.ToArray()
+}
+
+func (pq *PriorityQueue[T]) owns(handle *PriorityItem[T]) bool {
+	return handle != nil && handle.queue == pq && handle.index >= 0
+}
+
+func (pq *PriorityQueue[T]) removeAt(index int) T {
+	handle := pq.items[index]
+	last := len(pq.items) - 1
+	if index != last {
+		pq.swap(index, last)
+	}
+	pq.items[last] = nil
+	pq.items = pq.items[:last]
+	if index != last {
+		pq.fix(index)
+	}
+	handle.index = -1
+	handle.queue = nil
+	return handle.value
+}
+
+func (pq *PriorityQueue[T]) fix(index int) {
+	if !pq.down(index) {
+		pq.up(index)
+	}
+}
+
+func (pq *PriorityQueue[T]) up(index int) {
+	for index > 0 {
+		parent := (index - 1) / 2
+		if !pq.less(pq.items[index].value, pq.items[parent].value) {
+			return
+		}
+		pq.swap(index, parent)
+		index = parent
+	}
+}
+
+// down moves the item at index toward the leaves and reports whether it moved
+func (pq *PriorityQueue[T]) down(index int) bool {
+	start := index
+	for {
+		child := 2*index + 1
+		if child >= len(pq.items) {
+			break
+		}
+		if right := child + 1; right < len(pq.items) && pq.less(pq.items[right].value, pq.items[child].value) {
+			child = right
+		}
+		if !pq.less(pq.items[child].value, pq.items[index].value) {
+			break
+		}
+		pq.swap(index, child)
+		index = child
+	}
+	return index > start
+}
+
+func (pq *PriorityQueue[T]) swap(i int, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+// TopK returns the k largest elements of the slice, largest first
+// items: The slice to search
+// k: The number of elements to return
+func TopK[T cmp.Ordered](items []T, k int) []T {
+	return TopKWhere(items, k, cmp.Less[T])
+}
+
+// TopKWhere returns the k greatest elements of the slice according to the less function, greatest first
+// items: The slice to search
+// k: The number of elements to return
+// less: The function that reports whether a is less than b
+func TopKWhere[T any](items []T, k int, less func(a T, b T) bool) []T {
+	return selectK(items, k, less)
+}
+
+// BottomK returns the k smallest elements of the slice, smallest first
+// items: The slice to search
+// k: The number of elements to return
+func BottomK[T cmp.Ordered](items []T, k int) []T {
+	return BottomKWhere(items, k, cmp.Less[T])
+}
+
+// BottomKWhere returns the k least elements of the slice according to the less function, least first
+// items: The slice to search
+// k: The number of elements to return
+// less: The function that reports whether a is less than b
+func BottomKWhere[T any](items []T, k int, less func(a T, b T) bool) []T {
+	return selectK(items, k, func(a T, b T) bool {
+		return less(b, a)
+	})
+}
+
+// selectK keeps the k greatest elements in a heap whose root is the least of them, so each
+// remaining element costs one comparison plus O(log k) when it displaces the root
+func selectK[T any](items []T, k int, less func(a T, b T) bool) []T {
+	k = min(k, len(items))
+	if k <= 0 {
+		return []T{}
+	}
+
+	heap := NewPriorityQueue(less)
+	for _, item := range items {
+		if heap.Count() < k {
+			heap.Push(item)
+			continue
+		}
+		if root := heap.items[0]; less(root.value, item) {
+			heap.Update(root, item)
+		}
+	}
+
+	selected := make([]T, k)
+	for i := k - 1; i >= 0; i-- {
+		selected[i], _ = heap.Pop()
+	}
+	return selected
+}
@@ -0,0 +1,197 @@
+package ectolinq
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type priorityTask struct {
+	Name     string
+	Priority int
+}
+
+func drainPriorityQueue[T any](pq *PriorityQueue[T]) []T {
+	var items []T
+	for item, ok := pq.Pop(); ok; item, ok = pq.Pop() {
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestNewPriorityQueue(t *testing.T) {
+	t.Run("Create new priority queue", func(t *testing.T) {
+		pq := NewMinPriorityQueue[int]()
+		require.NotNil(t, pq)
+		assert.Equal(t, 0, pq.Count())
+		_, ok := pq.Pop()
+		assert.False(t, ok, "Pop should return false for an empty queue")
+		_, ok = pq.Peek()
+		assert.False(t, ok, "Peek should return false for an empty queue")
+	})
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	t.Run("Min queue pops the smallest item first", func(t *testing.T) {
+		pq := NewMinPriorityQueue[int]()
+		for _, n := range []int{5, 1, 4, 1, 3} {
+			pq.Push(n)
+		}
+		peeked, _ := pq.Peek()
+		assert.Equal(t, 1, peeked)
+		assert.Equal(t, []int{1, 1, 3, 4, 5}, drainPriorityQueue(pq))
+	})
+
+	t.Run("Max queue pops the largest item first", func(t *testing.T) {
+		pq := NewMaxPriorityQueue[string]()
+		for _, s := range []string{"b", "c", "a"} {
+			pq.Push(s)
+		}
+		assert.Equal(t, []string{"c", "b", "a"}, drainPriorityQueue(pq))
+	})
+
+	t.Run("Key selector queues", func(t *testing.T) {
+		tasks := []priorityTask{{"low", 1}, {"high", 9}, {"mid", 5}}
+		minQueue := NewMinPriorityQueueBy(func(t priorityTask) int { return t.Priority })
+		maxQueue := NewMaxPriorityQueueBy(func(t priorityTask) int { return t.Priority })
+		for _, task := range tasks {
+			minQueue.Push(task)
+			maxQueue.Push(task)
+		}
+		first, _ := minQueue.Pop()
+		assert.Equal(t, "low", first.Name)
+		first, _ = maxQueue.Pop()
+		assert.Equal(t, "high", first.Name)
+	})
+
+	t.Run("Random input pops in sorted order", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		pq := NewMinPriorityQueue[int]()
+		var expected []int
+		for i := 0; i < 1000; i++ {
+			n := rng.Intn(500)
+			expected = append(expected, n)
+			pq.Push(n)
+		}
+		slices.Sort(expected)
+		assert.Equal(t, expected, drainPriorityQueue(pq))
+	})
+}
+
+func TestPriorityQueueHandles(t *testing.T) {
+	t.Run("Update changes the priority", func(t *testing.T) {
+		pq := NewMinPriorityQueueBy(func(t priorityTask) int { return t.Priority })
+		pq.Push(priorityTask{"a", 1})
+		b := pq.Push(priorityTask{"b", 2})
+		pq.Push(priorityTask{"c", 3})
+
+		assert.True(t, pq.Update(b, priorityTask{"b", 0}))
+		first, _ := pq.Peek()
+		assert.Equal(t, "b", first.Name)
+
+		assert.True(t, pq.Update(b, priorityTask{"b", 10}))
+		names := Map(drainPriorityQueue(pq), func(t priorityTask) string { return t.Name })
+		assert.Equal(t, []string{"a", "c", "b"}, names)
+	})
+
+	t.Run("Fix restores order after an in-place change", func(t *testing.T) {
+		pq := NewMinPriorityQueueBy(func(t *priorityTask) int { return t.Priority })
+		a := &priorityTask{"a", 1}
+		b := &priorityTask{"b", 2}
+		pq.Push(a)
+		handle := pq.Push(b)
+		b.Priority = 0
+		assert.True(t, pq.Fix(handle))
+		first, _ := pq.Pop()
+		assert.Equal(t, "b", first.Name)
+	})
+
+	t.Run("Remove by handle", func(t *testing.T) {
+		pq := NewMinPriorityQueue[int]()
+		handles := Map([]int{5, 3, 8, 1, 9, 2}, func(n int) *PriorityItem[int] { return pq.Push(n) })
+		removed, ok := pq.Remove(handles[2])
+		assert.True(t, ok)
+		assert.Equal(t, 8, removed)
+		assert.Equal(t, 8, handles[2].Value())
+		assert.Equal(t, []int{1, 2, 3, 5, 9}, drainPriorityQueue(pq))
+	})
+
+	t.Run("Stale handles are rejected", func(t *testing.T) {
+		pq := NewMinPriorityQueue[int]()
+		other := NewMinPriorityQueue[int]()
+		handle := pq.Push(1)
+		otherHandle := other.Push(1)
+
+		assert.False(t, pq.Update(otherHandle, 0), "Handles from another queue should be rejected")
+		assert.False(t, pq.Fix(nil))
+
+		pq.Pop()
+		assert.False(t, pq.Update(handle, 2), "Popped handles should be rejected")
+		_, ok := pq.Remove(handle)
+		assert.False(t, ok)
+
+		handle = pq.Push(3)
+		pq.Clear()
+		assert.False(t, pq.Fix(handle), "Cleared handles should be rejected")
+		assert.Equal(t, 0, pq.Count())
+	})
+}
+
+func TestPriorityQueueContains(t *testing.T) {
+	pq := NewMinPriorityQueue[int]()
+	pq.Push(3)
+	pq.Push(1)
+	pq.Push(2)
+	assert.True(t, pq.Contains(2))
+	assert.False(t, pq.Contains(4))
+	assert.True(t, pq.ContainsWhere(func(n int) bool { return n > 2 }))
+	assert.ElementsMatch(t, []int{1, 2, 3}, pq.ToArray())
+	assert.ElementsMatch(t, List[int]{1, 2, 3}, pq.ToList())
+	assert.Equal(t, 1, pq.ToArray()[0], "The first element in heap order should be the root")
+}
+
+func TestTopK(t *testing.T) {
+	items := []int{5, 1, 9, 3, 7, 9, 2}
+
+	t.Run("TopK returns the largest first", func(t *testing.T) {
+		assert.Equal(t, []int{9, 9, 7}, TopK(items, 3))
+		assert.Equal(t, []int{5, 1, 9, 3, 7, 9, 2}, items, "Input should not be modified")
+	})
+
+	t.Run("BottomK returns the smallest first", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, BottomK(items, 3))
+	})
+
+	t.Run("k larger than the slice", func(t *testing.T) {
+		assert.Equal(t, []int{9, 9, 7, 5, 3, 2, 1}, TopK(items, 10))
+	})
+
+	t.Run("k of zero or less", func(t *testing.T) {
+		assert.Empty(t, TopK(items, 0))
+		assert.Empty(t, BottomK(items, -1))
+		assert.Empty(t, TopK([]int{}, 3))
+	})
+
+	t.Run("Custom less function", func(t *testing.T) {
+		tasks := []priorityTask{{"a", 3}, {"b", 1}, {"c", 2}}
+		byPriority := func(a, b priorityTask) bool { return a.Priority < b.Priority }
+		assert.Equal(t, "a", TopKWhere(tasks, 1, byPriority)[0].Name)
+		assert.Equal(t, []string{"b", "c"}, Map(BottomKWhere(tasks, 2, byPriority), func(t priorityTask) string { return t.Name }))
+	})
+
+	t.Run("Matches sorting", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		random := make([]int, 500)
+		for i := range random {
+			random[i] = rng.Intn(100)
+		}
+		sorted := slices.Clone(random)
+		slices.Sort(sorted)
+		assert.Equal(t, sorted[:20], BottomK(random, 20))
+		slices.Reverse(sorted)
+		assert.Equal(t, sorted[:20], TopK(random, 20))
+	})
+}
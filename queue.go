@@ -1,70 +1,113 @@
 package ectolinq
 
+// Queue is a first-in, first-out collection backed by a growable ring buffer.
+// Enqueue and Dequeue are amortized O(1)
 type Queue[T any] struct {
-	items List[T]
+	items ringBuffer[T]
 }
 
 // NewQueue creates a new queue
 func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{}
+}
+
+// NewQueueWithCapacity creates a new queue that can hold capacity items before it needs to grow
+// capacity: The number of items to allocate space for
+func NewQueueWithCapacity[T any](capacity int) *Queue[T] {
 	return &Queue[T]{
-		items: NewList[T](),
+		items: newRingBuffer[T](capacity),
 	}
 }
 
-// ToQueue creates a new queue from an array
+// ToQueue creates a new queue from an array. The first element of the array is at the front of the queue
 // items: The array to create the queue from
 func ToQueue[T any](items []T) *Queue[T] {
 	return &Queue[T]{
-		items: items,
+		items: ringBufferFrom(items),
 	}
 }
 
 // Enqueue adds an item to the end of the queue
 // item: The item to add
 func (q *Queue[T]) Enqueue(item T) {
-	q.items.Push(item)
+	q.items.pushBack(item)
 }
 
-// Dequeue removes and returns the item at the beginning of the queue
+// Dequeue removes and returns the item at the beginning of the queue.
+// It returns the zero value if the queue is empty; use TryDequeue to tell the difference
 func (q *Queue[T]) Dequeue() T {
-	item, items := q.items.Pop()
-	q.items = items
+	item, _ := q.items.popFront()
 	return item
 }
 
-// Peek returns an element that is at the beginning of the queue without removing it
+// TryDequeue removes and returns the item at the beginning of the queue, or false if the queue is empty
+func (q *Queue[T]) TryDequeue() (T, bool) {
+	return q.items.popFront()
+}
+
+// Peek returns an element that is at the beginning of the queue without removing it.
+// It returns the zero value if the queue is empty; use TryPeek to tell the difference
 func (q *Queue[T]) Peek() T {
-	return q.items.First()
+	item, _ := q.items.peekFront()
+	return item
+}
+
+// TryPeek returns the item at the beginning of the queue without removing it, or false if the queue is empty
+func (q *Queue[T]) TryPeek() (T, bool) {
+	return q.items.peekFront()
 }
 
 // Count returns the number of items in the queue
 func (q *Queue[T]) Count() int {
-	return q.items.Length()
+	return q.items.len()
 }
 
-// Clear removes all items from the queue
+// Capacity returns the number of items the queue can hold before it needs to grow
+func (q *Queue[T]) Capacity() int {
+	return q.items.capacity()
+}
+
+// EnsureCapacity grows the queue so it can hold at least capacity items without another allocation
+// capacity: The minimum capacity
+func (q *Queue[T]) EnsureCapacity(capacity int) {
+	q.items.grow(capacity)
+}
+
+// TrimExcess shrinks the capacity of the queue to the number of items it holds
+func (q *Queue[T]) TrimExcess() {
+	q.items.resize(q.items.len())
+}
+
+// Clear removes all items from the queue. The capacity is kept
 func (q *Queue[T]) Clear() {
-	q.items = NewList[T]()
+	q.items.clear()
 }
 
 // Contains returns if the queue contains the given item
 // item: The item to check for
 func (q *Queue[T]) Contains(item T) bool {
-	return q.items.Contains(item)
+	return q.ContainsWhere(func(other T) bool {
+		return Equals(other, item)
+	})
 }
 
 // ContainsWhere returns if the queue contains an item that satisfies the given predicate
 // fn: The predicate to check for
 func (q *Queue[T]) ContainsWhere(fn func(T) bool) bool {
-	return q.items.Any(fn)
+	for i := 0; i < q.items.len(); i++ {
+		if fn(q.items.at(i)) {
+			return true
+		}
+	}
+	return false
 }
 
-// ToArray returns the items in the queue as an array
+// ToArray returns the items in the queue as an array, from front to back
 func (q *Queue[T]) ToArray() []T {
-	return q.items
+	return q.items.toSlice()
 }
 
-// ToList returns the items in the queue as a list
+// ToList returns the items in the queue as a list, from front to back
 func (q *Queue[T]) ToList() List[T] {
-	return q.items
+	return q.ToArray()
 }
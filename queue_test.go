@@ -0,0 +1,177 @@
+package ectolinq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueue(t *testing.T) {
+	t.Run("Create new queue", func(t *testing.T) {
+		q := NewQueue[int]()
+		assert.NotNil(t, q)
+		assert.Equal(t, 0, q.Count())
+	})
+
+	t.Run("Create new queue with capacity", func(t *testing.T) {
+		q := NewQueueWithCapacity[int](16)
+		assert.Equal(t, 0, q.Count())
+		assert.Equal(t, 16, q.Capacity())
+	})
+}
+
+func TestToQueue(t *testing.T) {
+	t.Run("Create queue from slice", func(t *testing.T) {
+		slice := []int{1, 2, 3}
+		q := ToQueue(slice)
+		assert.Equal(t, 3, q.Count())
+		assert.Equal(t, slice, q.ToArray())
+	})
+
+	t.Run("Queue does not share the slice", func(t *testing.T) {
+		slice := []int{1, 2, 3}
+		q := ToQueue(slice)
+		q.Dequeue()
+		q.Enqueue(4)
+		assert.Equal(t, []int{1, 2, 3}, slice)
+	})
+}
+
+func TestQueueFIFO(t *testing.T) {
+	t.Run("Items are dequeued in the order they were enqueued", func(t *testing.T) {
+		q := NewQueue[int]()
+		q.Enqueue(1)
+		q.Enqueue(2)
+		q.Enqueue(3)
+		assert.Equal(t, 1, q.Dequeue())
+		assert.Equal(t, 2, q.Dequeue())
+		assert.Equal(t, 3, q.Dequeue())
+		assert.Equal(t, 0, q.Count())
+	})
+
+	t.Run("Queue created from a slice dequeues from the first element", func(t *testing.T) {
+		q := ToQueue([]string{"a", "b"})
+		q.Enqueue("c")
+		assert.Equal(t, "a", q.Dequeue())
+		assert.Equal(t, "b", q.Dequeue())
+		assert.Equal(t, "c", q.Dequeue())
+	})
+
+	t.Run("Order is kept across growth and wrap-around", func(t *testing.T) {
+		q := NewQueueWithCapacity[int](4)
+		next, expected := 0, 0
+		for round := 0; round < 50; round++ {
+			for i := 0; i < round%7+1; i++ {
+				q.Enqueue(next)
+				next++
+			}
+			for i := 0; i < round%5; i++ {
+				item, ok := q.TryDequeue()
+				if !ok {
+					break
+				}
+				assert.Equal(t, expected, item)
+				expected++
+			}
+		}
+		for q.Count() > 0 {
+			assert.Equal(t, expected, q.Dequeue())
+			expected++
+		}
+		assert.Equal(t, next, expected, "Every enqueued item should be dequeued exactly once")
+	})
+}
+
+func TestQueueTryDequeue(t *testing.T) {
+	t.Run("TryDequeue from non-empty queue", func(t *testing.T) {
+		q := ToQueue([]int{1, 2})
+		item, ok := q.TryDequeue()
+		assert.True(t, ok)
+		assert.Equal(t, 1, item)
+		assert.Equal(t, 1, q.Count())
+	})
+
+	t.Run("TryDequeue from empty queue", func(t *testing.T) {
+		q := NewQueue[int]()
+		_, ok := q.TryDequeue()
+		assert.False(t, ok)
+		assert.Equal(t, 0, q.Dequeue(), "Dequeue should return the zero value when empty")
+	})
+}
+
+func TestQueuePeek(t *testing.T) {
+	t.Run("Peek from non-empty queue", func(t *testing.T) {
+		q := ToQueue([]int{1, 2, 3})
+		assert.Equal(t, 1, q.Peek())
+		item, ok := q.TryPeek()
+		assert.True(t, ok)
+		assert.Equal(t, 1, item)
+		assert.Equal(t, 3, q.Count(), "Peek should not remove the item")
+	})
+
+	t.Run("Peek from empty queue", func(t *testing.T) {
+		q := NewQueue[int]()
+		_, ok := q.TryPeek()
+		assert.False(t, ok)
+		assert.Equal(t, 0, q.Peek())
+	})
+}
+
+func TestQueueCapacity(t *testing.T) {
+	t.Run("EnsureCapacity grows the queue", func(t *testing.T) {
+		q := ToQueue([]int{1, 2})
+		q.EnsureCapacity(100)
+		assert.GreaterOrEqual(t, q.Capacity(), 100)
+		assert.Equal(t, []int{1, 2}, q.ToArray())
+	})
+
+	t.Run("TrimExcess shrinks the queue", func(t *testing.T) {
+		q := NewQueueWithCapacity[int](100)
+		q.Enqueue(1)
+		q.Enqueue(2)
+		q.Dequeue()
+		q.TrimExcess()
+		assert.Equal(t, 1, q.Capacity())
+		assert.Equal(t, []int{2}, q.ToArray())
+		q.Enqueue(3)
+		assert.Equal(t, []int{2, 3}, q.ToArray())
+	})
+}
+
+func TestQueueClear(t *testing.T) {
+	t.Run("Clear queue", func(t *testing.T) {
+		q := ToQueue([]int{1, 2, 3})
+		q.Clear()
+		assert.Equal(t, 0, q.Count())
+		q.Enqueue(4)
+		assert.Equal(t, 4, q.Dequeue())
+	})
+}
+
+func TestQueueContains(t *testing.T) {
+	t.Run("Check if queue contains item", func(t *testing.T) {
+		q := ToQueue([]int{1, 2, 3})
+		assert.True(t, q.Contains(2))
+		assert.False(t, q.Contains(4))
+	})
+}
+
+func TestQueueContainsWhere(t *testing.T) {
+	t.Run("Check if queue contains item satisfying condition", func(t *testing.T) {
+		q := ToQueue([]int{1, 2, 3})
+		assert.True(t, q.ContainsWhere(func(i int) bool { return i > 2 }))
+		assert.False(t, q.ContainsWhere(func(i int) bool { return i > 3 }))
+	})
+}
+
+func TestQueueToList(t *testing.T) {
+	t.Run("Convert wrapped queue to list", func(t *testing.T) {
+		q := NewQueueWithCapacity[int](3)
+		q.Enqueue(1)
+		q.Enqueue(2)
+		q.Dequeue()
+		q.Enqueue(3)
+		q.Enqueue(4)
+		assert.Equal(t, List[int]{2, 3, 4}, q.ToList())
+	})
+}
@@ -0,0 +1,143 @@
+package ectolinq
+
+// ringBuffer is a growable circular buffer. The zero value is an empty buffer ready to use
+type ringBuffer[T any] struct {
+	items []T
+	head  int
+	count int
+}
+
+func newRingBuffer[T any](capacity int) ringBuffer[T] {
+	return ringBuffer[T]{items: make([]T, max(capacity, 0))}
+}
+
+// ringBufferFrom creates a buffer holding a copy of the items
+func ringBufferFrom[T any](items []T) ringBuffer[T] {
+	buffer := newRingBuffer[T](len(items))
+	buffer.count = copy(buffer.items, items)
+	return buffer
+}
+
+func (r *ringBuffer[T]) len() int {
+	return r.count
+}
+
+func (r *ringBuffer[T]) capacity() int {
+	return len(r.items)
+}
+
+// index maps a logical position to a position in items
+func (r *ringBuffer[T]) index(i int) int {
+	i += r.head
+	if i >= len(r.items) {
+		i -= len(r.items)
+	}
+	return i
+}
+
+func (r *ringBuffer[T]) at(i int) T {
+	return r.items[r.index(i)]
+}
+
+func (r *ringBuffer[T]) pushBack(item T) {
+	if r.count == len(r.items) {
+		r.grow(r.count + 1)
+	}
+	r.items[r.index(r.count)] = item
+	r.count++
+}
+
+func (r *ringBuffer[T]) pushFront(item T) {
+	if r.count == len(r.items) {
+		r.grow(r.count + 1)
+	}
+	r.head--
+	if r.head < 0 {
+		r.head += len(r.items)
+	}
+	r.items[r.head] = item
+	r.count++
+}
+
+func (r *ringBuffer[T]) popFront() (T, bool) {
+	var zero T
+	if r.count == 0 {
+		return zero, false
+	}
+	item := r.items[r.head]
+	r.items[r.head] = zero
+	r.head = r.index(1)
+	r.count--
+	return item, true
+}
+
+func (r *ringBuffer[T]) peekFront() (T, bool) {
+	if r.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.items[r.head], true
+}
+
+func (r *ringBuffer[T]) popBack() (T, bool) {
+	var zero T
+	if r.count == 0 {
+		return zero, false
+	}
+	last := r.index(r.count - 1)
+	item := r.items[last]
+	r.items[last] = zero
+	r.count--
+	return item, true
+}
+
+func (r *ringBuffer[T]) peekBack() (T, bool) {
+	if r.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.items[r.index(r.count-1)], true
+}
+
+// grow ensures the buffer can hold at least minCapacity items without another allocation.
+// Capacity at least doubles so pushes stay amortized O(1)
+func (r *ringBuffer[T]) grow(minCapacity int) {
+	if minCapacity <= len(r.items) {
+		return
+	}
+	r.resize(max(minCapacity, 2*len(r.items), 4))
+}
+
+// resize moves the items to the front of a new backing array of the given capacity
+func (r *ringBuffer[T]) resize(capacity int) {
+	items := make([]T, capacity)
+	r.copyTo(items)
+	r.items = items
+	r.head = 0
+}
+
+// copyTo copies the items in order to dst, which must have room for them
+func (r *ringBuffer[T]) copyTo(dst []T) {
+	if r.count == 0 {
+		return
+	}
+	end := r.head + r.count
+	if end <= len(r.items) {
+		copy(dst, r.items[r.head:end])
+		return
+	}
+	n := copy(dst, r.items[r.head:])
+	copy(dst[n:], r.items[:end-len(r.items)])
+}
+
+func (r *ringBuffer[T]) toSlice() []T {
+	items := make([]T, r.count)
+	r.copyTo(items)
+	return items
+}
+
+func (r *ringBuffer[T]) clear() {
+	clear(r.items)
+	r.head = 0
+	r.count = 0
+}
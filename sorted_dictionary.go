@@ -0,0 +1,434 @@
+package ectolinq
+
+import (
+	"cmp"
+	"iter"
+)
+
+// SortedDictionary is a dictionary that keeps its keys in ascending order.
+// It is backed by an AVL tree, so lookups, inserts and removals are O(log n)
+type SortedDictionary[K cmp.Ordered, V any] struct {
+	root  *sortedNode[K, V]
+	count int
+}
+
+type sortedNode[K cmp.Ordered, V any] struct {
+	key    K
+	value  V
+	left   *sortedNode[K, V]
+	right  *sortedNode[K, V]
+	height int
+}
+
+// NewSortedDictionary creates a new sorted dictionary
+func NewSortedDictionary[K cmp.Ordered, V any]() *SortedDictionary[K, V] {
+	return &SortedDictionary[K, V]{}
+}
+
+// ToSortedDictionary creates a new sorted dictionary from a map
+// m: The map to create the dictionary from
+func ToSortedDictionary[K cmp.Ordered, V any](m map[K]V) *SortedDictionary[K, V] {
+	d := NewSortedDictionary[K, V]()
+	d.MergeMaps(m)
+	return d
+}
+
+// Get returns the value in the dictionary for the given key
+// key: The key to get the value for
+func (d *SortedDictionary[K, V]) Get(key K) (V, bool) {
+	if node := d.find(key); node != nil {
+		return node.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets the value in the dictionary for the given key
+// key: The key to set the value for
+// value: The value to set
+func (d *SortedDictionary[K, V]) Set(key K, value V) {
+	var added bool
+	d.root, added = d.root.insert(key, value)
+	if added {
+		d.count++
+	}
+}
+
+// Keys returns the keys in the dictionary in ascending order
+func (d *SortedDictionary[K, V]) Keys() []K {
+	keys := make([]K, 0, d.count)
+	for key := range d.All() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ContainsKey returns if the dictionary contains the given key
+// key: The key to check for
+func (d *SortedDictionary[K, V]) ContainsKey(key K) bool {
+	return d.find(key) != nil
+}
+
+// ContainsValue returns if the dictionary contains the given value
+// value: The value to check for
+func (d *SortedDictionary[K, V]) ContainsValue(value V) bool {
+	return d.ContainsWhere(func(_ K, v V) bool {
+		return Equals(v, value)
+	})
+}
+
+// ContainsWhere returns if the dictionary contains a value that satisfies the given predicate
+// fn: The predicate to check for
+func (d *SortedDictionary[K, V]) ContainsWhere(fn func(K, V) bool) bool {
+	for key, value := range d.All() {
+		if fn(key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the value in the dictionary for the given key
+// key: The key to remove the value for
+func (d *SortedDictionary[K, V]) Remove(key K) {
+	var removed bool
+	d.root, removed = d.root.delete(key)
+	if removed {
+		d.count--
+	}
+}
+
+// RemoveValue removes the given value from the dictionary
+// value: The value to remove
+func (d *SortedDictionary[K, V]) RemoveValue(value V) {
+	d.RemoveWhere(func(_ K, v V) bool {
+		return Equals(v, value)
+	})
+}
+
+// RemoveWhere removes the value in the dictionary that satisfies the given predicate
+// fn: The predicate to check for
+func (d *SortedDictionary[K, V]) RemoveWhere(fn func(K, V) bool) {
+	var keys []K
+	for key, value := range d.All() {
+		if fn(key, value) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		d.Remove(key)
+	}
+}
+
+// Merge merges the given dictionaries into the dictionary
+// dicts: The dictionaries to merge
+func (d *SortedDictionary[K, V]) Merge(dicts ...SortedDictionary[K, V]) *SortedDictionary[K, V] {
+	for _, dict := range dicts {
+		for key, value := range dict.All() {
+			d.Set(key, value)
+		}
+	}
+	return d
+}
+
+// MergeMaps merges the given maps into the dictionary
+// maps: The maps to merge
+func (d *SortedDictionary[K, V]) MergeMaps(maps ...map[K]V) {
+	for _, m := range maps {
+		for key, value := range m {
+			d.Set(key, value)
+		}
+	}
+}
+
+// Clear removes all values from the dictionary
+func (d *SortedDictionary[K, V]) Clear() {
+	d.root = nil
+	d.count = 0
+}
+
+// Count returns the number of values in the dictionary
+func (d *SortedDictionary[K, V]) Count() int {
+	return d.count
+}
+
+// ToArray returns the values in the dictionary as an array ordered by key
+func (d *SortedDictionary[K, V]) ToArray() []V {
+	values := make([]V, 0, d.count)
+	for _, value := range d.All() {
+		values = append(values, value)
+	}
+	return values
+}
+
+// ToList returns the values in the dictionary as a list ordered by key
+func (d *SortedDictionary[K, V]) ToList() List[V] {
+	return d.ToArray()
+}
+
+// ToMap returns the dictionary as a new map
+func (d *SortedDictionary[K, V]) ToMap() map[K]V {
+	m := make(map[K]V, d.count)
+	for key, value := range d.All() {
+		m[key] = value
+	}
+	return m
+}
+
+// Values returns the values in the dictionary ordered by key
+func (d *SortedDictionary[K, V]) Values() []V {
+	return d.ToArray()
+}
+
+// All returns an iterator over the key-value pairs in ascending key order
+func (d *SortedDictionary[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.root.ascend(nil, nil, yield)
+	}
+}
+
+// Backward returns an iterator over the key-value pairs in descending key order
+func (d *SortedDictionary[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.root.descend(yield)
+	}
+}
+
+// Range returns an iterator over the key-value pairs with keys in the half-open range [lo, hi), in ascending order
+// lo: The inclusive lower bound
+// hi: The exclusive upper bound
+func (d *SortedDictionary[K, V]) Range(lo K, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.root.ascend(&lo, &hi, yield)
+	}
+}
+
+// From returns an iterator over the key-value pairs with keys greater than or equal to lo, in ascending order
+// lo: The inclusive lower bound
+func (d *SortedDictionary[K, V]) From(lo K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.root.ascend(&lo, nil, yield)
+	}
+}
+
+// DeleteRange removes every key in the half-open range [lo, hi) and returns the number removed
+// lo: The inclusive lower bound
+// hi: The exclusive upper bound
+func (d *SortedDictionary[K, V]) DeleteRange(lo K, hi K) int {
+	var keys []K
+	for key := range d.Range(lo, hi) {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		d.Remove(key)
+	}
+	return len(keys)
+}
+
+// Min returns the smallest key and its value, or false if the dictionary is empty
+func (d *SortedDictionary[K, V]) Min() (K, V, bool) {
+	node := d.root
+	if node == nil {
+		return zeroEntry[K, V]()
+	}
+	for node.left != nil {
+		node = node.left
+	}
+	return node.key, node.value, true
+}
+
+// Max returns the largest key and its value, or false if the dictionary is empty
+func (d *SortedDictionary[K, V]) Max() (K, V, bool) {
+	node := d.root
+	if node == nil {
+		return zeroEntry[K, V]()
+	}
+	for node.right != nil {
+		node = node.right
+	}
+	return node.key, node.value, true
+}
+
+// Floor returns the largest key less than or equal to the given key and its value, or false if there is none
+// key: The key to search for
+func (d *SortedDictionary[K, V]) Floor(key K) (K, V, bool) {
+	var best *sortedNode[K, V]
+	for node := d.root; node != nil; {
+		switch c := cmp.Compare(key, node.key); {
+		case c == 0:
+			return node.key, node.value, true
+		case c < 0:
+			node = node.left
+		default:
+			best = node
+			node = node.right
+		}
+	}
+	if best == nil {
+		return zeroEntry[K, V]()
+	}
+	return best.key, best.value, true
+}
+
+// Ceiling returns the smallest key greater than or equal to the given key and its value, or false if there is none
+// key: The key to search for
+func (d *SortedDictionary[K, V]) Ceiling(key K) (K, V, bool) {
+	var best *sortedNode[K, V]
+	for node := d.root; node != nil; {
+		switch c := cmp.Compare(key, node.key); {
+		case c == 0:
+			return node.key, node.value, true
+		case c > 0:
+			node = node.right
+		default:
+			best = node
+			node = node.left
+		}
+	}
+	if best == nil {
+		return zeroEntry[K, V]()
+	}
+	return best.key, best.value, true
+}
+
+func (d *SortedDictionary[K, V]) find(key K) *sortedNode[K, V] {
+	node := d.root
+	for node != nil {
+		switch c := cmp.Compare(key, node.key); {
+		case c == 0:
+			return node
+		case c < 0:
+			node = node.left
+		default:
+			node = node.right
+		}
+	}
+	return nil
+}
+
+func zeroEntry[K any, V any]() (K, V, bool) {
+	var zeroK K
+	var zeroV V
+	return zeroK, zeroV, false
+}
+
+// ascend yields the nodes in order, limited to [lo, hi) when the bounds are not nil. It returns false once yield does
+func (n *sortedNode[K, V]) ascend(lo *K, hi *K, yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	aboveLo := lo == nil || cmp.Compare(n.key, *lo) >= 0
+	belowHi := hi == nil || cmp.Compare(n.key, *hi) < 0
+	if aboveLo && !n.left.ascend(lo, hi, yield) {
+		return false
+	}
+	if aboveLo && belowHi && !yield(n.key, n.value) {
+		return false
+	}
+	if belowHi {
+		return n.right.ascend(lo, hi, yield)
+	}
+	return true
+}
+
+// descend yields the nodes in reverse order. It returns false once yield does
+func (n *sortedNode[K, V]) descend(yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	return n.right.descend(yield) && yield(n.key, n.value) && n.left.descend(yield)
+}
+
+func (n *sortedNode[K, V]) insert(key K, value V) (*sortedNode[K, V], bool) {
+	if n == nil {
+		return &sortedNode[K, V]{key: key, value: value, height: 1}, true
+	}
+
+	var added bool
+	switch c := cmp.Compare(key, n.key); {
+	case c == 0:
+		n.value = value
+		return n, false
+	case c < 0:
+		n.left, added = n.left.insert(key, value)
+	default:
+		n.right, added = n.right.insert(key, value)
+	}
+	return n.rebalance(), added
+}
+
+func (n *sortedNode[K, V]) delete(key K) (*sortedNode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var removed bool
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		n.left, removed = n.left.delete(key)
+	case c > 0:
+		n.right, removed = n.right.delete(key)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		n.key, n.value = successor.key, successor.value
+		n.right, _ = n.right.delete(successor.key)
+		removed = true
+	}
+	return n.rebalance(), removed
+}
+
+func (n *sortedNode[K, V]) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *sortedNode[K, V]) updateHeight() {
+	n.height = max(n.left.getHeight(), n.right.getHeight()) + 1
+}
+
+func (n *sortedNode[K, V]) rebalance() *sortedNode[K, V] {
+	n.updateHeight()
+	balance := n.left.getHeight() - n.right.getHeight()
+	switch {
+	case balance > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case balance < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+func (n *sortedNode[K, V]) rotateLeft() *sortedNode[K, V] {
+	pivot := n.right
+	n.right = pivot.left
+	pivot.left = n
+	n.updateHeight()
+	pivot.updateHeight()
+	return pivot
+}
+
+func (n *sortedNode[K, V]) rotateRight() *sortedNode[K, V] {
+	pivot := n.left
+	n.left = pivot.right
+	pivot.right = n
+	n.updateHeight()
+	pivot.updateHeight()
+	return pivot
+}
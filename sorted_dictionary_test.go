@@ -0,0 +1,195 @@
+package ectolinq
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSortedDictionary() *SortedDictionary[int, string] {
+	return ToSortedDictionary(map[int]string{50: "e", 10: "a", 30: "c", 20: "b", 40: "d"})
+}
+
+// checkSortedNode verifies the AVL ordering and balance invariants and returns the subtree height
+func checkSortedNode(t *testing.T, n *sortedNode[int, int]) int {
+	if n == nil {
+		return 0
+	}
+	if n.left != nil {
+		require.Less(t, n.left.key, n.key)
+	}
+	if n.right != nil {
+		require.Greater(t, n.right.key, n.key)
+	}
+	left := checkSortedNode(t, n.left)
+	right := checkSortedNode(t, n.right)
+	require.LessOrEqual(t, left-right, 1)
+	require.GreaterOrEqual(t, left-right, -1)
+	require.Equal(t, max(left, right)+1, n.height)
+	return n.height
+}
+
+func TestNewSortedDictionary(t *testing.T) {
+	d := NewSortedDictionary[string, int]()
+	require.NotNil(t, d, "NewSortedDictionary should not return nil")
+	assert.Equal(t, 0, d.Count(), "New dictionary should be empty")
+	_, _, ok := d.Min()
+	assert.False(t, ok, "Min should return false for an empty dictionary")
+	_, _, ok = d.Max()
+	assert.False(t, ok, "Max should return false for an empty dictionary")
+}
+
+func TestSortedDictionaryGetSet(t *testing.T) {
+	d := newTestSortedDictionary()
+	value, ok := d.Get(30)
+	assert.True(t, ok, "Get should return true for existing key")
+	assert.Equal(t, "c", value)
+
+	_, ok = d.Get(35)
+	assert.False(t, ok, "Get should return false for nonexistent key")
+
+	d.Set(30, "C")
+	assert.Equal(t, 5, d.Count(), "Overwriting a key should not change the count")
+	value, _ = d.Get(30)
+	assert.Equal(t, "C", value)
+}
+
+func TestSortedDictionaryOrder(t *testing.T) {
+	d := newTestSortedDictionary()
+	assert.Equal(t, []int{10, 20, 30, 40, 50}, d.Keys(), "Keys should be in ascending order")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, d.Values(), "Values should be ordered by key")
+	assert.Equal(t, List[string]{"a", "b", "c", "d", "e"}, d.ToList())
+	assert.Equal(t, map[int]string{10: "a", 20: "b", 30: "c", 40: "d", 50: "e"}, d.ToMap())
+
+	var backward []int
+	for key := range d.Backward() {
+		backward = append(backward, key)
+	}
+	assert.Equal(t, []int{50, 40, 30, 20, 10}, backward, "Backward should be in descending order")
+}
+
+func TestSortedDictionaryContains(t *testing.T) {
+	d := newTestSortedDictionary()
+	assert.True(t, d.ContainsKey(20))
+	assert.False(t, d.ContainsKey(25))
+	assert.True(t, d.ContainsValue("d"))
+	assert.True(t, d.ContainsWhere(func(k int, v string) bool { return k > 40 && v == "e" }))
+}
+
+func TestSortedDictionaryRemove(t *testing.T) {
+	d := newTestSortedDictionary()
+	d.Remove(30)
+	d.Remove(99)
+	assert.Equal(t, []int{10, 20, 40, 50}, d.Keys())
+	assert.Equal(t, 4, d.Count())
+
+	d.RemoveValue("a")
+	assert.Equal(t, []int{20, 40, 50}, d.Keys())
+
+	d.RemoveWhere(func(k int, _ string) bool { return k >= 40 })
+	assert.Equal(t, []int{20}, d.Keys())
+
+	d.Clear()
+	assert.Equal(t, 0, d.Count())
+}
+
+func TestSortedDictionaryMerge(t *testing.T) {
+	d := newTestSortedDictionary()
+	d.Merge(*ToSortedDictionary(map[int]string{5: "z", 10: "A"}))
+	d.MergeMaps(map[int]string{60: "f"})
+	assert.Equal(t, []int{5, 10, 20, 30, 40, 50, 60}, d.Keys())
+	value, _ := d.Get(10)
+	assert.Equal(t, "A", value, "Merge should overwrite existing keys")
+}
+
+func TestSortedDictionaryRange(t *testing.T) {
+	d := newTestSortedDictionary()
+
+	collect := func(seq func(func(int, string) bool)) []int {
+		var keys []int
+		for key := range seq {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	assert.Equal(t, []int{20, 30}, collect(d.Range(15, 40)), "Range should be half-open")
+	assert.Equal(t, []int{20, 30, 40}, collect(d.Range(20, 41)))
+	assert.Empty(t, collect(d.Range(31, 39)))
+	assert.Empty(t, collect(d.Range(40, 20)), "Inverted range should be empty")
+	assert.Equal(t, []int{40, 50}, collect(d.From(35)))
+
+	var first []int
+	for key := range d.Range(0, 100) {
+		first = append(first, key)
+		if len(first) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{10, 20}, first, "Range should support early exit")
+}
+
+func TestSortedDictionaryFloorCeiling(t *testing.T) {
+	d := newTestSortedDictionary()
+
+	key, value, ok := d.Floor(35)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+	assert.Equal(t, "c", value)
+
+	key, _, _ = d.Floor(30)
+	assert.Equal(t, 30, key, "Floor should include an exact match")
+
+	_, _, ok = d.Floor(5)
+	assert.False(t, ok, "Floor should return false below the minimum")
+
+	key, _, ok = d.Ceiling(35)
+	assert.True(t, ok)
+	assert.Equal(t, 40, key)
+
+	_, _, ok = d.Ceiling(55)
+	assert.False(t, ok, "Ceiling should return false above the maximum")
+
+	key, value, _ = d.Min()
+	assert.Equal(t, 10, key)
+	assert.Equal(t, "a", value)
+	key, value, _ = d.Max()
+	assert.Equal(t, 50, key)
+	assert.Equal(t, "e", value)
+}
+
+func TestSortedDictionaryDeleteRange(t *testing.T) {
+	d := newTestSortedDictionary()
+	removed := d.DeleteRange(20, 50)
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, []int{10, 50}, d.Keys())
+	assert.Equal(t, 2, d.Count())
+}
+
+func TestSortedDictionaryStaysBalanced(t *testing.T) {
+	d := NewSortedDictionary[int, int]()
+	expected := make(map[int]int)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		key := rng.Intn(1000)
+		if rng.Intn(3) == 0 {
+			d.Remove(key)
+			delete(expected, key)
+		} else {
+			d.Set(key, i)
+			expected[key] = i
+		}
+	}
+
+	checkSortedNode(t, d.root)
+	assert.Equal(t, len(expected), d.Count())
+	assert.Equal(t, expected, d.ToMap())
+
+	keys := Keys(expected)
+	slices.Sort(keys)
+	assert.Equal(t, keys, d.Keys())
+}